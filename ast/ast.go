@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"strings"
 	"yap/token"
 )
@@ -86,7 +87,6 @@ func (p *PostfixExpression) String() string {
 	var msg bytes.Buffer
 	msg.WriteString("(")
 	msg.WriteString(p.Left.String())
-	msg.WriteString(" " + p.Operator + " ")
 	msg.WriteString(p.Operator)
 	msg.WriteString(")")
 
@@ -136,6 +136,8 @@ type SayStatement struct {
 	Token token.Token
 	Name  *Identifier
 	Value Expression
+	// Pattern replaces Name when the value is destructured, like the [a, b] in `propose [a, b] = pair;`
+	Pattern *Pattern
 }
 
 func (s *SayStatement) statementNode() {}
@@ -148,7 +150,11 @@ func (s *SayStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(s.TokenLiteral() + " ")
-	out.WriteString(s.Name.String())
+	if s.Pattern != nil {
+		out.WriteString(s.Pattern.String())
+	} else {
+		out.WriteString(s.Name.String())
+	}
 	out.WriteString(" = ")
 
 	if s.Value != nil {
@@ -158,6 +164,63 @@ func (s *SayStatement) String() string {
 	return out.String()
 }
 
+// Pattern is the left side of a destructuring propose. Its Token is the `[`, `(`
+// or `{` it starts with, which tells whether it takes apart an array, a tuple or a hash
+type Pattern struct {
+	Token    token.Token
+	Elements []*PatternElement
+	// Rest is the name after `...` that gets whatever the other elements did not take
+	Rest *Identifier
+}
+
+// PatternElement is one element of a Pattern, like `b = 2` in [a, b = 2] or `age: years` in {age: years}
+type PatternElement struct {
+	// Key is the key looked up in a hash pattern, nil in array and tuple patterns
+	Key *Identifier
+	// Target is an *Identifier or a nested *Pattern
+	Target  Node
+	Default Expression
+}
+
+func (p *Pattern) TokenLiteral() string {
+	return p.Token.Literal
+}
+
+func (p *Pattern) String() string {
+	elements := []string{}
+	for _, element := range p.Elements {
+		var out bytes.Buffer
+		if element.Key != nil {
+			out.WriteString(element.Key.String())
+			if ident, ok := element.Target.(*Identifier); !ok || ident.Value != element.Key.Value {
+				out.WriteString(": " + element.Target.String())
+			}
+		} else {
+			out.WriteString(element.Target.String())
+		}
+		if element.Default != nil {
+			out.WriteString(" = " + element.Default.String())
+		}
+		elements = append(elements, out.String())
+	}
+	if p.Rest != nil {
+		elements = append(elements, "..."+p.Rest.String())
+	}
+
+	list := strings.Join(elements, ", ")
+	switch p.Token.Type {
+	case token.LBRACKET:
+		return "[" + list + "]"
+	case token.LBRACE:
+		return "{" + list + "}"
+	default:
+		if len(elements) == 1 {
+			return "(" + list + ",)"
+		}
+		return "(" + list + ")"
+	}
+}
+
 type PotentialStatement struct {
 	Token token.Token
 	Name  *Identifier
@@ -181,6 +244,31 @@ func (p *PotentialStatement) String() string {
 	return msg.String()
 }
 
+// AssignStatement is an assignment to an element like `arr[i] = x`, or a compound
+// assignment like `a += 1` or `counts["x"] *= 2`
+type AssignStatement struct {
+	Token    token.Token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+func (a *AssignStatement) statementNode() {}
+func (a *AssignStatement) TokenLiteral() string {
+	return a.Token.Literal
+}
+
+func (a *AssignStatement) String() string {
+	var msg bytes.Buffer
+
+	msg.WriteString(a.Target.String())
+	msg.WriteString(" " + a.Operator + " ")
+	msg.WriteString(a.Value.String())
+	msg.WriteString(";")
+
+	return msg.String()
+}
+
 type ConstStaement struct {
 	Token token.Token
 	Name  *Identifier
@@ -254,6 +342,8 @@ func (e *ExpressionStatement) String() string {
 type IntegerLiteral struct {
 	Token token.Token
 	Value int64
+	// Big is set instead of Value when the literal does not fit in an int64
+	Big *big.Int
 }
 
 func (i *IntegerLiteral) expressionNode() {}
@@ -266,6 +356,22 @@ func (i *IntegerLiteral) String() string {
 	return i.Token.Literal
 }
 
+// DecimalLiteral is a number with a `d` suffix, Value holds it without the suffix
+type DecimalLiteral struct {
+	Token token.Token
+	Value string
+}
+
+func (d *DecimalLiteral) expressionNode() {}
+
+func (d *DecimalLiteral) TokenLiteral() string {
+	return d.Token.Literal
+}
+
+func (d *DecimalLiteral) String() string {
+	return d.Token.Literal
+}
+
 type FloatLiteral struct {
 	Token token.Token
 	Value float64
@@ -356,17 +462,15 @@ func (t *TernaryExpression) TokenLiteral() string {
 
 func (t *TernaryExpression) String() string {
 	var msg bytes.Buffer
-	msg.WriteString("if")
+	msg.WriteString("(")
 	msg.WriteString(t.Condition.String())
-	msg.WriteString("then")
+	msg.WriteString(" ? ")
 	msg.WriteString(t.Consequence.String())
+	msg.WriteString(" : ")
+	msg.WriteString(t.Alternative.String())
+	msg.WriteString(")")
 
-	if t.Alternative != nil {
-		msg.WriteString("else")
-		msg.WriteString(t.Alternative.String())
-	}
 	return msg.String()
-
 }
 
 type BlockStatement struct {
@@ -391,7 +495,11 @@ type FunctionExpression struct {
 	Token      token.Token
 	Name       *Identifier
 	Parameters []*Identifier
-	Body       *BlockStatement
+	// Defaults holds the default value of every parameter, nil for the required ones
+	Defaults []Expression
+	// Rest is the `...name` parameter collecting the extra arguments into an array
+	Rest *Identifier
+	Body *BlockStatement
 }
 
 func (f *FunctionExpression) expressionNode() {}
@@ -401,16 +509,37 @@ func (f *FunctionExpression) TokenLiteral() string {
 
 func (f *FunctionExpression) String() string {
 	var msg bytes.Buffer
-	param := []string{}
-	for _, p := range f.Parameters {
-		param = append(param, p.String())
+
+	msg.WriteString(Signature(f.Name, f.Parameters, f.Defaults, f.Rest))
+	msg.WriteString(" ")
+	msg.WriteString(f.Body.String())
+
+	return msg.String()
+}
+
+// Signature writes the head of a function like `func add(a, b = 1, ...rest)`
+func Signature(name *Identifier, params []*Identifier, defaults []Expression, rest *Identifier) string {
+	var msg bytes.Buffer
+
+	list := []string{}
+	for i, p := range params {
+		if i < len(defaults) && defaults[i] != nil {
+			list = append(list, p.String()+" = "+defaults[i].String())
+		} else {
+			list = append(list, p.String())
+		}
+	}
+	if rest != nil {
+		list = append(list, "..."+rest.String())
 	}
 
-	msg.WriteString(f.TokenLiteral())
+	msg.WriteString("func")
+	if name != nil {
+		msg.WriteString(" " + name.String())
+	}
 	msg.WriteString("(")
-	msg.WriteString(strings.Join(param, ", "))
-	msg.WriteString(") ")
-	msg.WriteString(f.Body.String())
+	msg.WriteString(strings.Join(list, ", "))
+	msg.WriteString(")")
 
 	return msg.String()
 }
@@ -419,6 +548,13 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	// NamedArguments are the `name = value` arguments, they always come after the others
+	NamedArguments []*NamedArgument
+}
+
+type NamedArgument struct {
+	Name  *Identifier
+	Value Expression
 }
 
 func (c *CallExpression) expressionNode() {}
@@ -434,6 +570,9 @@ func (c *CallExpression) String() string {
 	for _, a := range c.Arguments {
 		args = append(args, a.String())
 	}
+	for _, a := range c.NamedArguments {
+		args = append(args, a.Name.String()+" = "+a.Value.String())
+	}
 
 	msg.WriteString(c.Function.String())
 	msg.WriteString("(")
@@ -457,9 +596,41 @@ func (s *StringLiteral) String() string {
 	return s.Literal
 }
 
+// InterpolatedString is a string like "score: {total}/{num:>3}". Texts holds the
+// text around the interpolated Values, so it has one more element, and Formats
+// holds the format specifier of every value, empty when there is none
+type InterpolatedString struct {
+	Token   token.Token
+	Texts   []string
+	Values  []Expression
+	Formats []string
+}
+
+func (is *InterpolatedString) expressionNode() {}
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *InterpolatedString) String() string {
+	var msg bytes.Buffer
+
+	msg.WriteString(is.Texts[0])
+	for i, value := range is.Values {
+		msg.WriteString("{" + value.String())
+		if is.Formats[i] != "" {
+			msg.WriteString(":" + is.Formats[i])
+		}
+		msg.WriteString("}" + is.Texts[i+1])
+	}
+
+	return msg.String()
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	// ElementType is the declared type of a typed array like `[int: 1, 2]`, nil otherwise
+	ElementType *Identifier
 }
 
 func (a *ArrayLiteral) expressionNode() {}
@@ -475,12 +646,59 @@ func (a *ArrayLiteral) String() string {
 		elementMsg = append(elementMsg, e.String())
 	}
 	msg.WriteString("[")
+	if a.ElementType != nil {
+		msg.WriteString(a.ElementType.String() + ":")
+		if len(a.Elements) != 0 {
+			msg.WriteString(" ")
+		}
+	}
 	msg.WriteString(strings.Join(elementMsg, ", "))
 	msg.WriteString("]")
 
 	return msg.String()
 }
 
+// SetLiteral is `{1, 2, 3}`, told apart from a hash by the missing colons
+type SetLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (s *SetLiteral) expressionNode() {}
+func (s *SetLiteral) TokenLiteral() string {
+	return s.Token.Literal
+}
+
+func (s *SetLiteral) String() string {
+	elements := []string{}
+	for _, e := range s.Elements {
+		elements = append(elements, e.String())
+	}
+	return "{" + strings.Join(elements, ", ") + "}"
+}
+
+// TupleLiteral is `(1, 2)`, a tuple of one element needs a trailing comma: `(1,)`
+type TupleLiteral struct {
+	Token    token.Token
+	Elements []Expression
+}
+
+func (t *TupleLiteral) expressionNode() {}
+func (t *TupleLiteral) TokenLiteral() string {
+	return t.Token.Literal
+}
+
+func (t *TupleLiteral) String() string {
+	elements := []string{}
+	for _, e := range t.Elements {
+		elements = append(elements, e.String())
+	}
+	if len(elements) == 1 {
+		return "(" + elements[0] + ",)"
+	}
+	return "(" + strings.Join(elements, ", ") + ")"
+}
+
 type IndexExpression struct {
 	Token token.Token
 	Left  Expression
@@ -507,6 +725,8 @@ func (i *IndexExpression) String() string {
 type HashLiteral struct {
 	Token token.Token
 	Pairs map[Expression]Expression
+	// Keys holds the keys of Pairs in the order they were written
+	Keys []Expression
 }
 
 func (h *HashLiteral) expressionNode() {}
@@ -519,8 +739,8 @@ func (h *HashLiteral) String() string {
 
 	pairs := []string{}
 
-	for key, val := range h.Pairs {
-		pairs = append(pairs, (key.String() + ": " + val.String()))
+	for _, key := range h.Keys {
+		pairs = append(pairs, (key.String() + ": " + h.Pairs[key].String()))
 	}
 
 	msg.WriteString("{")
@@ -561,6 +781,33 @@ func (f *ForExpression) String() string {
 	return msg.String()
 }
 
+// ForInExpression is `for (x in xs) { ... }`, x is bound anew for every element
+type ForInExpression struct {
+	Token      token.Token
+	Variable   *Identifier
+	Iterable   Expression
+	Statements *BlockStatement
+}
+
+func (f *ForInExpression) statementNode() {}
+func (f *ForInExpression) TokenLiteral() string {
+	return f.Token.Literal
+}
+
+func (f *ForInExpression) String() string {
+	var msg bytes.Buffer
+
+	msg.WriteString("for")
+	msg.WriteString("(")
+	msg.WriteString(f.Variable.String() + " in " + f.Iterable.String())
+	msg.WriteString(")")
+	msg.WriteString("{")
+	msg.WriteString(f.Statements.String())
+	msg.WriteString("}")
+
+	return msg.String()
+}
+
 type ForExecution struct {
 	For ForExpression
 }
@@ -569,3 +816,49 @@ func (f *ForExecution) statementNode() {}
 func (f *ForExecution) String() string {
 	return f.For.String()
 }
+
+type ImportStatement struct {
+	Token token.Token
+	Path  string
+	Name  *Identifier
+}
+
+func (i *ImportStatement) statementNode() {}
+func (i *ImportStatement) TokenLiteral() string {
+	return i.Token.Literal
+}
+
+func (i *ImportStatement) String() string {
+	var msg bytes.Buffer
+
+	msg.WriteString(i.TokenLiteral() + " ")
+	msg.WriteString("\"" + i.Path + "\"")
+	msg.WriteString(" as ")
+	msg.WriteString(i.Name.String())
+	msg.WriteString(";")
+
+	return msg.String()
+}
+
+type MemberExpression struct {
+	Token  token.Token
+	Left   Expression
+	Member *Identifier
+}
+
+func (m *MemberExpression) expressionNode() {}
+func (m *MemberExpression) TokenLiteral() string {
+	return m.Token.Literal
+}
+
+func (m *MemberExpression) String() string {
+	var msg bytes.Buffer
+
+	msg.WriteString("(")
+	msg.WriteString(m.Left.String())
+	msg.WriteString(".")
+	msg.WriteString(m.Member.String())
+	msg.WriteString(")")
+
+	return msg.String()
+}
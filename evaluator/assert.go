@@ -0,0 +1,129 @@
+package evaluator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"yap/object"
+)
+
+// assertError calls a function, so like arrayBuiltins the assertions are added in init
+func init() {
+	builtins["assert"] = &object.Builtin{Fn: assertBuiltin}
+	builtins["assertEqual"] = &object.Builtin{Fn: assertEqualBuiltin}
+	builtins["assertNotEqual"] = &object.Builtin{Fn: assertNotEqualBuiltin}
+	builtins["assertApprox"] = &object.Builtin{Fn: assertApproxBuiltin}
+	builtins["assertError"] = &object.Builtin{Fn: assertErrorBuiltin}
+}
+
+const defaultApproxTolerance = 1e-9
+
+// assert(condition, message?)
+func assertBuiltin(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wrong number of arguments, expect=1 or 2, got=%d", len(args))
+	}
+	if isTrue(args[0]) {
+		return NULL
+	}
+	return assertionFailure("assert", "condition is not true", args[1:])
+}
+
+// assertEqual(actual, expected, message?)
+func assertEqualBuiltin(args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError("wrong number of arguments, expect=2 or 3, got=%d", len(args))
+	}
+	if objectsEqual(args[0], args[1]) {
+		return NULL
+	}
+	detail := fmt.Sprintf("expect=%s, got=%s", describe(args[1]), describe(args[0]))
+	return assertionFailure("assertEqual", detail, args[2:])
+}
+
+// assertNotEqual(actual, unexpected, message?)
+func assertNotEqualBuiltin(args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError("wrong number of arguments, expect=2 or 3, got=%d", len(args))
+	}
+	if !objectsEqual(args[0], args[1]) {
+		return NULL
+	}
+	detail := fmt.Sprintf("both values are %s", describe(args[0]))
+	return assertionFailure("assertNotEqual", detail, args[2:])
+}
+
+// assertApprox(actual, expected, tolerance?)
+func assertApproxBuiltin(args ...object.Object) object.Object {
+	if len(args) < 2 || len(args) > 3 {
+		return newError("wrong number of arguments, expect=2 or 3, got=%d", len(args))
+	}
+
+	nums := []float64{}
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return newError("Argument type error: expect Int or Float, got %s", arg.Type())
+		}
+		nums = append(nums, toFloat(arg))
+	}
+
+	tolerance := defaultApproxTolerance
+	if len(nums) == 3 {
+		tolerance = nums[2]
+	}
+	if math.Abs(nums[0]-nums[1]) <= tolerance {
+		return NULL
+	}
+	detail := fmt.Sprintf("expect=%g (±%g), got=%g", nums[1], tolerance, nums[0])
+	return assertionFailure("assertApprox", detail, nil)
+}
+
+// assertError(fn, contains?) calls fn without arguments and expects it to fail.
+// The function is needed because an error passed as a plain argument would stop the call itself
+func assertErrorBuiltin(args ...object.Object) object.Object {
+	if len(args) < 1 || len(args) > 2 {
+		return newError("wrong number of arguments, expect=1 or 2, got=%d", len(args))
+	}
+
+	switch args[0].(type) {
+	case *object.Function, *object.Builtin:
+	default:
+		return newError("Argument type error: expect FUNCTION, got %s", args[0].Type())
+	}
+
+	result := applyFunction(args[0], []object.Object{})
+	errObj, ok := result.(*object.Error)
+	if !ok {
+		return assertionFailure("assertError", "expect an error, got "+describe(result), nil)
+	}
+
+	if len(args) == 2 {
+		substr, ok := args[1].(*object.String)
+		if !ok {
+			return newError("Argument type error: expect STRING, got %s", args[1].Type())
+		}
+		if !strings.Contains(errObj.Message, substr.Value) {
+			detail := fmt.Sprintf("expect error containing %q, got %q", substr.Value, errObj.Message)
+			return assertionFailure("assertError", detail, nil)
+		}
+	}
+	return NULL
+}
+
+func assertionFailure(name, detail string, message []object.Object) *object.Error {
+	if len(message) == 1 {
+		return newError("%s failed: %s (%s)", name, message[0].Inspect(), detail)
+	}
+	return newError("%s failed: %s", name, detail)
+}
+
+// describe renders an object for assertion messages, quoting strings so "1" and 1 differ
+func describe(obj object.Object) string {
+	if obj == nil {
+		return NULL.Inspect()
+	}
+	if str, ok := obj.(*object.String); ok {
+		return fmt.Sprintf("%q", str.Value)
+	}
+	return obj.Inspect()
+}
@@ -0,0 +1,230 @@
+package evaluator
+
+import (
+	"strings"
+	"yap/ast"
+	"yap/object"
+)
+
+// evalAssignStatement runs `target = value` for an index and `target op= value` for
+// a name or an index. The target is looked up once, so `arr[next()] += 1` calls next once
+func evalAssignStatement(node *ast.AssignStatement, env *object.Enviroment) object.Object {
+	operator := strings.TrimSuffix(node.Operator, "=")
+
+	switch target := node.Target.(type) {
+	case *ast.Identifier:
+		if !env.Exist(target.Value) {
+			return withPosition(newError("valariable %s does not exist, (perhaps not yet declare?)",
+				target.Value), target.Token)
+		}
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if operator != "" {
+			current, _ := env.Get(target.Value)
+			val = withPosition(evalCompound(current, operator, val, env), node.Token)
+			if isError(val) {
+				return val
+			}
+		}
+		return withPosition(setVariable(target.Value, val, env), node.Token)
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		if index == nil {
+			index = NULL
+		}
+		val := Eval(node.Value, env)
+		if isError(val) {
+			return val
+		}
+		if operator != "" {
+			current := elementAt(left, index)
+			if isError(current) {
+				return withPosition(current, target.Token)
+			}
+			val = withPosition(evalCompound(current, operator, val, env), node.Token)
+			if isError(val) {
+				return val
+			}
+		}
+		return withPosition(setIndex(left, index, val), target.Token)
+	default:
+		return withPosition(newError("cannot assign to %s", node.Target.String()), node.Token)
+	}
+}
+
+// setVariable sets an existing variable, keeping the type it already has
+func setVariable(name string, val object.Object, env *object.Enviroment) object.Object {
+	if !env.TypeComp(name, typeOf(val)) {
+		return newError("type mismatch error: could not set %s into '%s' variable (Type = %s)",
+			typeOf(val), name, env.GetType(name).Type())
+	}
+	env.Set(name, val)
+	return val
+}
+
+// evalCompound works out `current op val` for a compound assignment. Only numbers,
+// joining strings and repeating a string can be used, anything else is an error here
+// instead of whatever the infix operator would make of it
+func evalCompound(current object.Object, operator string, val object.Object, env *object.Enviroment) object.Object {
+	if current == nil {
+		current = NULL
+	}
+	if val == nil {
+		val = NULL
+	}
+
+	switch {
+	case isNumber(current) && isNumber(val):
+	case operator == "+" && current.Type() == object.STRING_OBJ &&
+		(val.Type() == object.STRING_OBJ || object.IsInteger(val.Type())):
+	case operator == "*" && current.Type() == object.STRING_OBJ && val.Type() == object.INTEGER_OBJ:
+	default:
+		return newError("unsupported operand types for %s=: %s and %s", operator, current.Type(), val.Type())
+	}
+	return evalInfixExpression(current, operator, val, env)
+}
+
+// elementAt is the current value of an element for a compound assignment, unlike
+// indexing it is an error when the element is not there
+func elementAt(left, index object.Object) object.Object {
+	switch left := left.(type) {
+	case *object.Array:
+		if index.Type() == object.BIG_INTEGER_OBJ {
+			return bigIndexError(index, "an array", len(left.Elements))
+		}
+		if index.Type() != object.INTEGER_OBJ {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		idx := index.(*object.Integer).Value
+		if idx < 0 || idx >= int64(len(left.Elements)) {
+			return newError("index %d out of range for an array of length %d", idx, len(left.Elements))
+		}
+		return left.Elements[idx]
+	case *object.Hash:
+		key, ok := object.AsHashable(index)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		pair, ok := left.Pairs[key.HashKey()]
+		if !ok {
+			if str, isString := index.(*object.String); isString {
+				return newError("the hash has no key %q", str.Value)
+			}
+			return newError("the hash has no key %s", index.Inspect())
+		}
+		return pair.Value
+	default:
+		return notAssignable(left)
+	}
+}
+
+// setIndex puts val into an array element or a hash entry, an array element has
+// to exist already while a hash entry is added when it does not
+func setIndex(left, index, val object.Object) object.Object {
+	if val == nil {
+		val = NULL
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		if index.Type() == object.BIG_INTEGER_OBJ {
+			return bigIndexError(index, "an array", len(left.Elements))
+		}
+		if index.Type() != object.INTEGER_OBJ {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		if left.Frozen {
+			return newError("cannot assign to an element of a frozen array")
+		}
+		idx := index.(*object.Integer).Value
+		if idx < 0 || idx >= int64(len(left.Elements)) {
+			return newError("index %d out of range for an array of length %d", idx, len(left.Elements))
+		}
+		if !left.Accepts(val) {
+			return newError("Type mismatch, cannot put %s into an array of %s", val.Type(), left.ElementType)
+		}
+		left.Elements[idx] = val
+	case *object.Hash:
+		key, ok := object.AsHashable(index)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.Set(key, val)
+	default:
+		return notAssignable(left)
+	}
+	return val
+}
+
+func notAssignable(left object.Object) *object.Error {
+	switch typeOf(left) {
+	case object.STRING_OBJ, object.TUPLE_OBJ:
+		return newError("cannot assign to an element of %s, it cannot be changed", left.Type())
+	default:
+		return newError("cannot assign to an element of %s, only arrays and hashes can be changed", typeOf(left))
+	}
+}
+
+// evalIncrementExpression runs `++x`, `--x`, `x++` and `x--` on a name or an element.
+// The prefix form gives the new value and the postfix form gives the old one
+func evalIncrementExpression(operator string, target ast.Expression, postfix bool,
+	env *object.Enviroment) object.Object {
+
+	var current object.Object
+	var set func(object.Object) object.Object
+
+	switch target := target.(type) {
+	case *ast.Identifier:
+		if !env.Exist(target.Value) {
+			return newError("valariable %s does not exist, (perhaps not yet declare?)", target.Value)
+		}
+		current, _ = env.Get(target.Value)
+		set = func(val object.Object) object.Object { return setVariable(target.Value, val, env) }
+	case *ast.IndexExpression:
+		left := Eval(target.Left, env)
+		if isError(left) {
+			return left
+		}
+		index := Eval(target.Index, env)
+		if isError(index) {
+			return index
+		}
+		if index == nil {
+			index = NULL
+		}
+		current = elementAt(left, index)
+		if isError(current) {
+			return current
+		}
+		set = func(val object.Object) object.Object { return setIndex(left, index, val) }
+	default:
+		return newError("cannot apply %s to %s, expected a name or an index", operator, target.String())
+	}
+
+	if current == nil || !isNumber(current) {
+		if postfix {
+			return newError("unknown operator: %s%s", typeOf(current), operator)
+		}
+		return newError("unknown operator: %s%s", operator, typeOf(current))
+	}
+	val := evalInfixExpression(current, operator[:1], &object.Integer{Value: 1}, env)
+	if isError(val) {
+		return val
+	}
+	if result := set(val); isError(result) {
+		return result
+	}
+	if postfix {
+		return current
+	}
+	return val
+}
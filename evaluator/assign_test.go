@@ -0,0 +1,168 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose a = 5; a += 2; a", "7"},
+		{"propose a = 5; a -= 7; a", "-2"},
+		{"propose a = 5; a *= 3; a", "15"},
+		{"propose a = 7; a /= 2; a", "3"},
+		{"propose a = 7; a %= 4; a", "3"},
+		{"propose a = 2; a **= 10; a", "1024"},
+		{"propose a = 2; a **= 64; a", "18446744073709551616"},
+		{"propose f = 1.5; f *= 2; f", "3"},
+		{"propose d = 0.1d; d += 0.2d; d", "0.3"},
+		{`propose s = "ab"; s += "c"; s`, "abc"},
+		{`propose s = "n"; s += 1; s`, "n1"},
+		{`propose s = "ab"; s *= 3; s`, "ababab"},
+		{"propose a = 1; a += 1 + 2 * 3; a", "8"},
+		{"propose a = 1; propose f = func() { a += 1; }; f(); f(); a", "3"},
+		{"propose a = 1; a += 1", "2"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose arr = [1, 2, 3]; arr[0] = 10; arr", "[10, 2, 3]"},
+		{"propose arr = [1, 2, 3]; arr[1] += 5; arr", "[1, 7, 3]"},
+		{"propose arr = [1, 2, 3]; arr[2] **= 2; arr", "[1, 2, 9]"},
+		{"propose arr = [1, 2.5]; arr[0] = \"x\"; arr", "[x, 2.5]"},
+		{"propose grid = [[0, 0], [0, 0]]; grid[1][0] = 7; grid", "[[0, 0], [7, 0]]"},
+		{"propose arr = [1]; propose same = arr; same[0] = 2; arr", "[2]"},
+		{`propose counts = {}; counts["x"] = 1; counts["x"] += 1; counts`, "{x: 2}"},
+		{`propose counts = {"a": 1, "b": 2}; counts["a"] = 5; counts`, "{a: 5, b: 2}"},
+		{`propose h = {}; h[freeze([1, 2])] = "p"; h[freeze([1, 2])]`, "p"},
+		{`propose h = {"s": "ab"}; h["s"] += "c"; h["s"]`, "abc"},
+		{"propose ids = [int: 1, 2]; ids[0] += 1; ids", "[2, 2]"},
+		{"propose arr = [1, 2]; arr[0] = 9", "9"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a += 1;", "valariable a does not exist, (perhaps not yet declare?)"},
+		{"propose a = 1; a += 0.5;", "type mismatch error: could not set FLOAT into 'a' variable (Type = INTEGER)"},
+		{"propose a = 1; a += \"x\";", "unsupported operand types for +=: INTEGER and STRING"},
+		{"propose b = true; b += 1;", "unsupported operand types for +=: BOOLEAN and INTEGER"},
+		{`propose s = "ab"; s -= "b";`, "unsupported operand types for -=: STRING and STRING"},
+		{`propose s = "ab"; s *= "b";`, "unsupported operand types for *=: STRING and STRING"},
+		{"propose arr = [1]; arr += 1;", "unsupported operand types for +=: ARRAY and INTEGER"},
+		{"propose a; a += 1;", "unsupported operand types for +=: NULL and INTEGER"},
+		{"propose a = 1; a /= 0;", "zero division error: 1 / 0"},
+		{"propose arr = [1, 2]; arr[2] = 3;", "index 2 out of range for an array of length 2"},
+		{"propose arr = [1, 2]; arr[-1] += 3;", "index -1 out of range for an array of length 2"},
+		{"propose arr = [1, 2]; arr[2 ** 64] = 3;", "index 18446744073709551616 out of range for an array of length 2"},
+		{"propose arr = [1, 2]; arr[2 ** 64] += 3;", "index 18446744073709551616 out of range for an array of length 2"},
+		{`propose arr = [1, 2]; arr["a"] = 3;`, "array index must be INTEGER, got STRING"},
+		{"propose arr = freeze([1, 2]); arr[0] = 3;", "cannot assign to an element of a frozen array"},
+		{"propose arr = freeze([1, 2]); arr[0] += 3;", "cannot assign to an element of a frozen array"},
+		{"propose ids = [int: 1, 2]; ids[0] = \"x\";", "Type mismatch, cannot put STRING into an array of INTEGER"},
+		{`propose h = {}; h["x"] += 1;`, `the hash has no key "x"`},
+		{`propose h = {1: 1}; h[2] -= 1;`, "the hash has no key 2"},
+		{`propose h = {}; h[[1]] = 1;`, "unusable as hash key: ARRAY"},
+		{`propose s = "abc"; s[0] = "x";`, "cannot assign to an element of STRING, it cannot be changed"},
+		{"propose t = (1, 2); t[0] += 1;", "cannot assign to an element of TUPLE, it cannot be changed"},
+		{"propose n = 5; n[0] = 1;", "cannot assign to an element of INTEGER, only arrays and hashes can be changed"},
+		{"propose arr = [1]; arr[0] = x;", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestIncrementDecrement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose a = 1; ++a", "2"},
+		{"propose a = 1; a++", "1"},
+		{"propose a = 1; a++; a", "2"},
+		{"propose a = 1; --a", "0"},
+		{"propose a = 1; a--", "1"},
+		{"propose a = 1; a--; a", "0"},
+		{"propose a = 1; [a++, a++, a]", "[1, 2, 3]"},
+		{"propose a = 1; [++a, ++a, a]", "[2, 3, 3]"},
+		{"propose a = 1; -a++", "-1"},
+		{"propose a = 1; a++ + 10", "11"},
+		{"propose a = 1; propose b = 5; a---b", "-4"},
+		{"propose f = 1.5; f++; f", "2.5"},
+		{"propose d = 1.5d; d--; d", "0.5"},
+		{"propose a = 9223372036854775807; a++; a", "9223372036854775808"},
+		{"propose arr = [1, 2]; arr[0]++; arr", "[2, 2]"},
+		{"propose arr = [1, 2]; [arr[1]--, arr]", "[2, [1, 1]]"},
+		{"propose arr = [1, 2]; ++arr[1]", "3"},
+		{`propose h = {"x": 1}; h["x"]++; h`, "{x: 2}"},
+		{`propose h = {"x": 1}; --h["x"]`, "0"},
+		{"propose a = 0; propose f = func() { a++ }; f(); f(); a", "2"},
+		{"propose total = 0; for (propose i = 0; i < 4; i++) { total += i; }; total", "6"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIncrementDecrementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a++;", "valariable a does not exist, (perhaps not yet declare?)"},
+		{"++len;", "valariable len does not exist, (perhaps not yet declare?)"},
+		{`propose s = "a"; s++;`, "unknown operator: STRING++"},
+		{`propose s = "a"; --s;`, "unknown operator: --STRING"},
+		{"propose a; a++;", "unknown operator: NULL++"},
+		{"propose b = true; ++b;", "unknown operator: ++BOOLEAN"},
+		{"5++;", "cannot apply ++ to 5, expected a name or an index"},
+		{"propose a = 1; ++(a + 1);", "cannot apply ++ to (a + 1), expected a name or an index"},
+		{"propose f = func() { 1 }; f()--;", "cannot apply -- to f(), expected a name or an index"},
+		{"propose arr = [1]; arr[1]++;", "index 1 out of range for an array of length 1"},
+		{`propose arr = ["a"]; arr[0]++;`, "unknown operator: STRING++"},
+		{"propose arr = freeze([1]); arr[0]++;", "cannot assign to an element of a frozen array"},
+		{`propose h = {}; h["x"]++;`, `the hash has no key "x"`},
+		{"propose t = (1, 2); t[0]++;", "cannot assign to an element of TUPLE, it cannot be changed"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
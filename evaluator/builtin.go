@@ -3,11 +3,13 @@ package evaluator
 import (
 	"bufio"
 	"fmt"
+	"math"
+	"math/big"
 	"math/rand"
 	"os"
-	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 	"yap/object"
 )
 
@@ -20,9 +22,15 @@ var builtins = map[string]*object.Builtin{
 
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Tuple:
+				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Set:
+				return &object.Integer{Value: int64(len(arg.Order))}
+			case *object.Hash:
+				return &object.Integer{Value: int64(len(arg.Pairs))}
 			default:
 				return newError("argument to `len` not supported, got %s",
 					args[0].Type())
@@ -30,6 +38,30 @@ var builtins = map[string]*object.Builtin{
 		},
 	},
 
+	// contains(str, substring) or contains(array, element)
+	"contains": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("contains", args, 2, 2); err != nil {
+				return err
+			}
+			idx := indexOf("contains", args[0], args[1])
+			if isError(idx) {
+				return idx
+			}
+			return nativeBoolToBooleanObject(idx.(*object.Integer).Value != -1)
+		},
+	},
+
+	// indexOf(str, substring) or indexOf(array, element), -1 when there is no match
+	"indexOf": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("indexOf", args, 2, 2); err != nil {
+				return err
+			}
+			return indexOf("indexOf", args[0], args[1])
+		},
+	},
+
 	"scan": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
 			if len(args) != 0 {
@@ -41,41 +73,56 @@ var builtins = map[string]*object.Builtin{
 			return &object.String{Value: sc.Text()}
 		},
 	},
-	//There are still problem appending another array into a 2d array
+	// append(arr, x, ...) returns a copy of arr with every x added as one element,
+	// so appending an array nests it. Use concat to join arrays
 	"append": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return newError("wrong number of argument, expected=2, got=%d", len(args))
+			if len(args) < 2 {
+				return newError("wrong number of arguments to `append`, expect at least 2, got=%d", len(args))
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("append", 0, object.ARRAY_OBJ, args[0])
 			}
 
-			switch arg := args[0].(type) {
-			case *object.Array:
-				var elements []object.Object
-				elements = append(elements, arg.Elements...)
-				newArr := &object.Array{Elements: elements}
-				if _, ok := arg.Elements[0].(*object.Array); ok {
-					if arr, ok := args[1].(*object.Array); ok {
-						newArr.Elements = append(newArr.Elements, arr)
-						return newArr
-					}
+			newArr := &object.Array{ElementType: arr.ElementType}
+			newArr.Elements = append(newArr.Elements, arr.Elements...)
+			for _, arg := range args[1:] {
+				if !arr.Accepts(arg) {
+					return newError("Appending error: cannot append %s to an array of %s",
+						arg.Type(), arr.ElementType)
 				}
-				if arr, ok := args[1].(*object.Array); ok {
-					for _, elemnt := range arr.Elements {
-						newArr.Elements = append(newArr.Elements, elemnt)
-					}
+				newArr.Elements = append(newArr.Elements, arg)
+			}
+			return newArr
+		},
+	},
 
-					return newArr
-				} else if reflect.TypeOf(arg.Elements[0]) == reflect.TypeOf(args[1]) {
-					var elements []object.Object
-					elements = append(elements, arg.Elements...)
-					elements = append(elements, args[1])
-					return &object.Array{Elements: elements}
-				} else {
-					return newError("Appending error: cannot append an array of %T with %T", arg.Elements[0], args[1])
+	// concat(a, b, ...) returns one array with the elements of every array, it is typed
+	// like the first one
+	"concat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments to `concat`, expect at least 1, got=0")
+			}
+			newArr := &object.Array{}
+			for i, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return argTypeError("concat", i, object.ARRAY_OBJ, arg)
+				}
+				if i == 0 {
+					newArr.ElementType = arr.ElementType
+				}
+				for _, element := range arr.Elements {
+					if !newArr.Accepts(element) {
+						return newError("Appending error: cannot append %s to an array of %s",
+							element.Type(), newArr.ElementType)
+					}
+					newArr.Elements = append(newArr.Elements, element)
 				}
-			default:
-				return newError("Function does not appending of %T and %T", args[0], args[1])
 			}
+			return newArr
 		},
 	},
 
@@ -84,14 +131,7 @@ var builtins = map[string]*object.Builtin{
 			msg := []string{}
 
 			for _, arg := range args {
-				switch arg.Inspect() {
-				case "\\t":
-					msg = append(msg, "\t")
-				case "\\n":
-					msg = append(msg, "\n")
-				default:
-					msg = append(msg, arg.Inspect())
-				}
+				msg = append(msg, arg.Inspect())
 			}
 			fmt.Print(strings.Join(msg, " "))
 			return nil
@@ -100,13 +140,16 @@ var builtins = map[string]*object.Builtin{
 
 	"pop": &object.Builtin{
 		Fn: func(args ...object.Object) object.Object {
-			if len(args) > 2 {
+			if len(args) < 1 || len(args) > 2 {
 				return newError("Unexpect amount of arguement, expect=2 (Array, index), or 1 (Array)")
 			}
+			if arr, ok := args[0].(*object.Array); ok && arr.Frozen {
+				return newError("cannot pop from a frozen array")
+			}
 			if len(args) == 2 {
 				if arr, ok := args[0].(*object.Array); ok {
 					if idx, ok := args[1].(*object.Integer); ok {
-						if len(arr.Elements) <= int(idx.Value) {
+						if idx.Value < 0 || int64(len(arr.Elements)) <= idx.Value {
 							return newError("Error: index out of range, array contain=%d elements",
 								len(arr.Elements))
 						}
@@ -127,6 +170,9 @@ var builtins = map[string]*object.Builtin{
 				}
 			}
 			if arr, ok := args[0].(*object.Array); ok {
+				if len(arr.Elements) == 0 {
+					return newError("cannot pop from an empty array")
+				}
 				index := len(arr.Elements) - 1
 				obj := arr.Elements[index]
 				arr.Elements = arr.Elements[:index]
@@ -169,8 +215,8 @@ var builtins = map[string]*object.Builtin{
 
 			value := []object.Object{}
 
-			for _, val := range hash.Pairs {
-				value = append(value, val.Value)
+			for _, pair := range hash.OrderedPairs() {
+				value = append(value, pair.Value)
 			}
 
 			return &object.Array{Elements: value}
@@ -201,21 +247,71 @@ var builtins = map[string]*object.Builtin{
 				return newError("Argument length error: expect=1, got=%d", len(args))
 			}
 			switch obj := args[0].(type) {
-			case *object.Integer:
+			case *object.Integer, *object.BigInteger:
 				return obj
+			case *object.Decimal:
+				return normalizeBigInt(obj.Int())
 			case *object.Float:
 				val := obj.Value
-				return &object.Integer{Value: int64(val)}
+				if math.IsNaN(val) || math.IsInf(val, 0) {
+					return newError("Cannot convert %g into an Int", val)
+				}
+				num, _ := big.NewFloat(val).Int(nil)
+				return normalizeBigInt(num)
 			case *object.String:
 				val := obj.Value
-				num, err := strconv.Atoi(val)
-				if err != nil {
+				num, ok := new(big.Int).SetString(val, 10)
+				if !ok {
 					return newError("Cannot convert %s into an Int", val)
 				}
-				return &object.Integer{Value: int64(num)}
+				return normalizeBigInt(num)
 			default:
 				return newError("Cannot convert %s into an Int", obj.Type())
 			}
 		},
 	},
+	"float": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("Argument length error: expect=1, got=%d", len(args))
+			}
+			switch obj := args[0].(type) {
+			case *object.Integer, *object.BigInteger, *object.Float, *object.Decimal:
+				return &object.Float{Value: toFloat(obj)}
+			case *object.String:
+				val, err := strconv.ParseFloat(obj.Value, 64)
+				if err != nil {
+					return newError("Cannot convert %s into a Float", obj.Value)
+				}
+				return &object.Float{Value: val}
+			default:
+				return newError("Cannot convert %s into a Float", obj.Type())
+			}
+		},
+	},
+}
+
+func indexOf(name string, haystack, needle object.Object) object.Object {
+	switch haystack := haystack.(type) {
+	case *object.String:
+		sub, ok := needle.(*object.String)
+		if !ok {
+			return argTypeError(name, 1, object.STRING_OBJ, needle)
+		}
+		idx := strings.Index(haystack.Value, sub.Value)
+		if idx > 0 {
+			// the index counts characters, not bytes
+			idx = utf8.RuneCountInString(haystack.Value[:idx])
+		}
+		return &object.Integer{Value: int64(idx)}
+	case *object.Array:
+		for i, element := range haystack.Elements {
+			if objectsEqual(element, needle) {
+				return &object.Integer{Value: int64(i)}
+			}
+		}
+		return &object.Integer{Value: -1}
+	default:
+		return newError("argument 1 to `%s` must be STRING or ARRAY, got %s", name, haystack.Type())
+	}
 }
@@ -0,0 +1,540 @@
+package evaluator
+
+import (
+	"sort"
+	"yap/object"
+)
+
+// arrayBuiltins call back into the evaluator, so they are added to builtins in init
+// to keep builtins out of its own initialization
+var arrayBuiltins map[string]*object.Builtin
+
+func init() {
+	arrayBuiltins = map[string]*object.Builtin{
+		// map(array, fn) calls fn(element, index) for every element
+		"map": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				arr, fn, err := arrayAndCallback("map", args)
+				if err != nil {
+					return err
+				}
+				elements := make([]object.Object, 0, len(arr.Elements))
+				for i, element := range arr.Elements {
+					result := callback("map", fn, 1, element, &object.Integer{Value: int64(i)})
+					if isError(result) {
+						return result
+					}
+					elements = append(elements, orNull(result))
+				}
+				return &object.Array{Elements: elements}
+			},
+		},
+
+		// filter(array, fn) keeps the elements for which fn(element, index) is truthy.
+		// filter, sort, reverse and slice keep the element type of a typed array
+		"filter": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				arr, fn, err := arrayAndCallback("filter", args)
+				if err != nil {
+					return err
+				}
+				elements := []object.Object{}
+				for i, element := range arr.Elements {
+					result := callback("filter", fn, 1, element, &object.Integer{Value: int64(i)})
+					if isError(result) {
+						return result
+					}
+					if isTrue(result) {
+						elements = append(elements, element)
+					}
+				}
+				return &object.Array{Elements: elements, ElementType: arr.ElementType}
+			},
+		},
+
+		// reduce(array, fn, initial?) folds the array with fn(accumulator, element, index),
+		// without initial the first element is used
+		"reduce": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount("reduce", args, 2, 3); err != nil {
+					return err
+				}
+				arr, fn, err := arrayAndCallback("reduce", args[:2])
+				if err != nil {
+					return err
+				}
+
+				elements := arr.Elements
+				start := 0
+				var acc object.Object
+				if len(args) == 3 {
+					acc = args[2]
+				} else {
+					if len(elements) == 0 {
+						return newError("cannot `reduce` an empty array without an initial value")
+					}
+					acc = elements[0]
+					start = 1
+				}
+
+				for i := start; i < len(elements); i++ {
+					acc = callback("reduce", fn, 2, acc, elements[i], &object.Integer{Value: int64(i)})
+					if isError(acc) {
+						return acc
+					}
+					acc = orNull(acc)
+				}
+				return acc
+			},
+		},
+
+		// any(array, fn?) and all(array, fn?) test fn(element, index), or the elements
+		// themselves when there is no fn
+		"any": quantifierBuiltin("any", true),
+		"all": quantifierBuiltin("all", false),
+
+		// find(array, fn) returns the first element for which fn is truthy, or null
+		"find": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				arr, fn, err := arrayAndCallback("find", args)
+				if err != nil {
+					return err
+				}
+				idx := findIndex("find", arr, fn)
+				if isError(idx) {
+					return idx
+				}
+				if i := idx.(*object.Integer).Value; i != -1 {
+					return arr.Elements[i]
+				}
+				return NULL
+			},
+		},
+
+		// findIndex(array, fn) returns the index of the first match, or -1
+		"findIndex": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				arr, fn, err := arrayAndCallback("findIndex", args)
+				if err != nil {
+					return err
+				}
+				return findIndex("findIndex", arr, fn)
+			},
+		},
+
+		// sort(array, cmp?) returns a sorted copy. Without cmp numbers and strings are
+		// sorted in their natural order, cmp(a, b) returns a negative, zero or positive
+		// Int, or a Bool telling whether a comes before b. Equal elements keep their order
+		"sort": &object.Builtin{
+			Fn: func(args ...object.Object) object.Object {
+				if err := checkArgCount("sort", args, 1, 2); err != nil {
+					return err
+				}
+				arr, ok := args[0].(*object.Array)
+				if !ok {
+					return argTypeError("sort", 0, object.ARRAY_OBJ, args[0])
+				}
+
+				less := func(a, b object.Object) (bool, object.Object) {
+					cmp, err := compareObjects(a, b)
+					if err != nil {
+						return false, newError("cannot `sort` without a comparator: %s", err.Message)
+					}
+					return cmp < 0, nil
+				}
+				if len(args) == 2 {
+					fn, err := callbackArg("sort", args, 1)
+					if err != nil {
+						return err
+					}
+					less = func(a, b object.Object) (bool, object.Object) {
+						return comparatorLess(fn, a, b)
+					}
+				}
+
+				elements := append([]object.Object{}, arr.Elements...)
+				var failure object.Object
+				sort.SliceStable(elements, func(i, j int) bool {
+					if failure != nil {
+						return false
+					}
+					result, err := less(elements[i], elements[j])
+					if err != nil {
+						failure = err
+					}
+					return result
+				})
+				if failure != nil {
+					return failure
+				}
+				return &object.Array{Elements: elements, ElementType: arr.ElementType}
+			},
+		},
+	}
+
+	for name, builtin := range arrayBuiltins {
+		builtins[name] = builtin
+	}
+	for name, builtin := range plainArrayBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+// plainArrayBuiltins never call a function, so the table is built before init
+// like the other builtin tables and init only adds it to builtins
+var plainArrayBuiltins = map[string]*object.Builtin{
+	// reverse(array) returns a reversed copy
+	"reverse": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("reverse", args, 1, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("reverse", 0, object.ARRAY_OBJ, args[0])
+			}
+			elements := make([]object.Object, len(arr.Elements))
+			for i, element := range arr.Elements {
+				elements[len(elements)-1-i] = element
+			}
+			return &object.Array{Elements: elements, ElementType: arr.ElementType}
+		},
+	},
+
+	// slice(array, start, end?) returns a copy of array[start:end], end defaults to the length.
+	// It also slices the characters of a string
+	"slice": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("slice", args, 2, 3); err != nil {
+				return err
+			}
+			if str, ok := args[0].(*object.String); ok {
+				return substringOf("slice", str.Value, args)
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return newError("argument 1 to `slice` must be ARRAY or STRING, got %s", args[0].Type())
+			}
+			start, err := intArg("slice", args, 1)
+			if err != nil {
+				return err
+			}
+			end := int64(len(arr.Elements))
+			if len(args) == 3 {
+				end, err = intArg("slice", args, 2)
+				if err != nil {
+					return err
+				}
+			}
+
+			if start < 0 || end > int64(len(arr.Elements)) || start > end {
+				return newError("slice range [%d:%d] out of bounds for array of length %d",
+					start, end, len(arr.Elements))
+			}
+			return &object.Array{Elements: append([]object.Object{}, arr.Elements[start:end]...), ElementType: arr.ElementType}
+		},
+	},
+
+	// flatten(array, depth?) splices nested arrays into their parent, depth defaults to 1
+	"flatten": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("flatten", args, 1, 2); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("flatten", 0, object.ARRAY_OBJ, args[0])
+			}
+			depth := int64(1)
+			if len(args) == 2 {
+				d, err := intArg("flatten", args, 1)
+				if err != nil {
+					return err
+				}
+				if d < 0 {
+					return newError("depth passed to `flatten` cannot be negative, got %d", d)
+				}
+				depth = d
+			}
+			return &object.Array{Elements: flatten(arr.Elements, depth)}
+		},
+	},
+
+	// zip(a, b, ...) pairs up the elements of every array, stopping at the shortest one
+	"zip": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments to `zip`, expect at least 2, got=%d", len(args))
+			}
+			arrays := []*object.Array{}
+			shortest := -1
+			for i, arg := range args {
+				arr, ok := arg.(*object.Array)
+				if !ok {
+					return argTypeError("zip", i, object.ARRAY_OBJ, arg)
+				}
+				if shortest == -1 || len(arr.Elements) < shortest {
+					shortest = len(arr.Elements)
+				}
+				arrays = append(arrays, arr)
+			}
+
+			elements := []object.Object{}
+			for i := 0; i < shortest; i++ {
+				group := []object.Object{}
+				for _, arr := range arrays {
+					group = append(group, arr.Elements[i])
+				}
+				elements = append(elements, &object.Array{Elements: group})
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// range(end), range(start, end) or range(start, end, step), end is never included
+	"range": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("range", args, 1, 3); err != nil {
+				return err
+			}
+			nums := []int64{}
+			for i := range args {
+				n, err := intArg("range", args, i)
+				if err != nil {
+					return err
+				}
+				nums = append(nums, n)
+			}
+
+			start, end, step := int64(0), nums[0], int64(1)
+			if len(nums) > 1 {
+				start, end = nums[0], nums[1]
+			}
+			if len(nums) == 3 {
+				step = nums[2]
+			}
+			if step == 0 {
+				return newError("step passed to `range` cannot be 0")
+			}
+
+			count := rangeLength(start, end, step)
+			if count > maxRangeLength {
+				return newError("`range` would make %d elements, the most is %d", count, maxRangeLength)
+			}
+			elements := make([]object.Object, count)
+			for i := range elements {
+				elements[i] = &object.Integer{Value: start + int64(i)*step}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// freeze(array) returns a frozen copy of array, nested arrays are frozen too.
+	// A frozen array cannot be changed and can be used as a hash key
+	"freeze": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("freeze", args, 1, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("freeze", 0, object.ARRAY_OBJ, args[0])
+			}
+			frozen, err := freeze(arr, map[*object.Array]bool{})
+			if err != nil {
+				return err
+			}
+			return frozen
+		},
+	},
+
+	// isFrozen(array) tells whether array was made by freeze
+	"isFrozen": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("isFrozen", args, 1, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("isFrozen", 0, object.ARRAY_OBJ, args[0])
+			}
+			return nativeBoolToBooleanObject(arr.Frozen)
+		},
+	},
+}
+
+// freeze copies arr, visiting tells which arrays are being frozen further up
+// since a frozen array cannot contain itself
+func freeze(arr *object.Array, visiting map[*object.Array]bool) (*object.Array, *object.Error) {
+	if arr.Frozen {
+		return arr, nil
+	}
+	if visiting[arr] {
+		return nil, newError("cannot freeze an array that contains itself")
+	}
+	visiting[arr] = true
+	defer delete(visiting, arr)
+	elements := make([]object.Object, len(arr.Elements))
+	for i, element := range arr.Elements {
+		if nested, ok := element.(*object.Array); ok {
+			frozen, err := freeze(nested, visiting)
+			if err != nil {
+				return nil, err
+			}
+			element = frozen
+		}
+		if _, ok := object.AsHashable(element); !ok {
+			return nil, newError("cannot freeze an array holding a %s, it is not hashable", element.Type())
+		}
+		elements[i] = element
+	}
+	return &object.Array{Elements: elements, ElementType: arr.ElementType, Frozen: true}, nil
+}
+
+func quantifierBuiltin(name string, any bool) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 1, 2); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError(name, 0, object.ARRAY_OBJ, args[0])
+			}
+			var fn object.Object
+			if len(args) == 2 {
+				f, err := callbackArg(name, args, 1)
+				if err != nil {
+					return err
+				}
+				fn = f
+			}
+
+			for i, element := range arr.Elements {
+				result := element
+				if fn != nil {
+					result = callback(name, fn, 1, element, &object.Integer{Value: int64(i)})
+					if isError(result) {
+						return result
+					}
+				}
+				if isTrue(result) == any {
+					return nativeBoolToBooleanObject(any)
+				}
+			}
+			return nativeBoolToBooleanObject(!any)
+		},
+	}
+}
+
+func findIndex(name string, arr *object.Array, fn object.Object) object.Object {
+	for i, element := range arr.Elements {
+		result := callback(name, fn, 1, element, &object.Integer{Value: int64(i)})
+		if isError(result) {
+			return result
+		}
+		if isTrue(result) {
+			return &object.Integer{Value: int64(i)}
+		}
+	}
+	return &object.Integer{Value: -1}
+}
+
+func comparatorLess(fn object.Object, a, b object.Object) (bool, object.Object) {
+	result := callback("sort", fn, 2, a, b)
+	switch result := result.(type) {
+	case *object.Error:
+		return false, result
+	case *object.Integer:
+		return result.Value < 0, nil
+	case *object.Boolean:
+		return result.Value, nil
+	default:
+		return false, newError("comparator passed to `sort` must return INTEGER or BOOLEAN, got %s",
+			typeOf(result))
+	}
+}
+
+func flatten(elements []object.Object, depth int64) []object.Object {
+	flat := []object.Object{}
+	for _, element := range elements {
+		if arr, ok := element.(*object.Array); ok && depth > 0 {
+			flat = append(flat, flatten(arr.Elements, depth-1)...)
+			continue
+		}
+		flat = append(flat, element)
+	}
+	return flat
+}
+
+func arrayAndCallback(name string, args []object.Object) (*object.Array, object.Object, *object.Error) {
+	if err := checkArgCount(name, args, 2, 2); err != nil {
+		return nil, nil, err
+	}
+	arr, ok := args[0].(*object.Array)
+	if !ok {
+		return nil, nil, argTypeError(name, 0, object.ARRAY_OBJ, args[0])
+	}
+	fn, err := callbackArg(name, args, 1)
+	if err != nil {
+		return nil, nil, err
+	}
+	return arr, fn, nil
+}
+
+func callbackArg(name string, args []object.Object, idx int) (object.Object, *object.Error) {
+	switch args[idx].(type) {
+	case *object.Function, *object.Builtin:
+		return args[idx], nil
+	default:
+		return nil, argTypeError(name, idx, object.FUNCTION_OBJ, args[idx])
+	}
+}
+
+// callback calls fn with as many of args as it takes, so `map(arr, func(x) {...})`
+// does not have to accept the index. Builtins only get the first required arguments.
+// Errors from fn are returned unchanged
+func callback(name string, fn object.Object, required int, args ...object.Object) object.Object {
+	switch fn := fn.(type) {
+	case *object.Function:
+		if fn.Required() > len(args) {
+			return newError("function passed to `%s` takes %d arguments, expect at most %d",
+				name, fn.Required(), len(args))
+		}
+		if fn.Rest == nil {
+			args = args[:min(len(fn.Parameters), len(args))]
+		}
+		return applyFunction(fn, args)
+	default:
+		return applyFunction(fn, args[:required])
+	}
+}
+
+func orNull(obj object.Object) object.Object {
+	if obj == nil {
+		return NULL
+	}
+	return obj
+}
+
+// maxRangeLength keeps a typo like range(10 ** 12) from eating all the memory
+const maxRangeLength = 1 << 24
+
+// rangeLength counts the elements of range(start, end, step) without overflowing,
+// the distance between start and end always fits in a uint64
+func rangeLength(start, end, step int64) uint64 {
+	var distance, stride uint64
+	switch {
+	case step > 0 && end > start:
+		distance, stride = uint64(end-start), uint64(step)
+	case step < 0 && end < start:
+		distance, stride = uint64(start-end), uint64(-step)
+	default:
+		return 0
+	}
+	count := distance / stride
+	if distance%stride != 0 {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,135 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+// inspected is the Inspect output expected from an array result
+type inspected string
+
+func TestArrayBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`map([1, 2, 3], func(x) { x * 2 })`, inspected("[2, 4, 6]")},
+		{`map([1, 2, 3], func(x, i) { x * i })`, inspected("[0, 2, 6]")},
+		{`map(["a", "bc"], len)`, inspected("[1, 2]")},
+		{`map([], func(x) { x })`, inspected("[]")},
+		{`map([1], func(x, i, j) { x })`, "function passed to `map` takes 3 arguments, expect at most 2"},
+		{`map([1], 2)`, "argument 2 to `map` must be FUNCTION, got INTEGER"},
+		{`map(1, len)`, "argument 1 to `map` must be ARRAY, got INTEGER"},
+		{`filter([1, 2, 3, 4], func(x) { x % 2 == 0 })`, inspected("[2, 4]")},
+		{`filter([1, 2, 3], func(x, i) { i != 1 })`, inspected("[1, 3]")},
+		{`reduce([1, 2, 3, 4], func(acc, x) { acc + x })`, 10},
+		{`reduce([1, 2, 3], func(acc, x) { acc + x }, 10)`, 16},
+		{`reduce([1, 2], func(acc, x) { acc + y })`, "identifier not found: y"},
+		{`any([1, 2, 3], func(x) { x > 2 })`, true},
+		{`any([1, 2, 3], func(x) { x > 3 })`, false},
+		{`any([cap, nocap])`, true},
+		{`any([])`, false},
+		{`all([])`, true},
+		{`reduce([], func(acc, x) { acc + x }, 0)`, 0},
+		{`reduce([], func(acc, x) { acc + x })`, "cannot `reduce` an empty array without an initial value"},
+		{`all([1, 2, 3], func(x) { x > 0 })`, true},
+		{`all([1, 2, 3], func(x) { x > 1 })`, false},
+		{`find([1, 2, 3, 4], func(x) { x > 2 })`, 3},
+		{`find([1, 2], func(x) { x > 2 })`, nil},
+		{`findIndex([1, 2, 3, 4], func(x) { x > 2 })`, 2},
+		{`findIndex([1, 2], func(x) { x > 2 })`, -1},
+		{`sort([3, 1, 2])`, inspected("[1, 2, 3]")},
+		{`sort([2.5, 1, 2])`, inspected("[1, 2, 2.5]")},
+		{`sort(["pear", "apple", "fig"])`, inspected("[apple, fig, pear]")},
+		{`sort([3, 1, 2], func(a, b) { b - a })`, inspected("[3, 2, 1]")},
+		{`sort([3, 1, 2], func(a, b) { a > b })`, inspected("[3, 2, 1]")},
+		{`sort([[2, 1], [1, 2], [2, 0], [1, 1]], func(a, b) { a[0] - b[0] })`,
+			inspected("[[1, 2], [1, 1], [2, 1], [2, 0]]")},
+		{`sort([[2], [1, 3], [1], ["a"]])`, "cannot `sort` without a comparator: cannot compare STRING and INTEGER"},
+		{`sort([[2], [1, 3], [], [1]])`, inspected("[[], [1], [1, 3], [2]]")},
+		{`sort([nocap, cap])`, "cannot `sort` without a comparator: cannot compare BOOLEAN and BOOLEAN"},
+		{`sort([1, 2], func(a, b) { "x" })`, "comparator passed to `sort` must return INTEGER or BOOLEAN, got STRING"},
+		{`propose arr = [3, 1, 2]; sort(arr); arr`, inspected("[3, 1, 2]")},
+		{`reverse([1, 2, 3])`, inspected("[3, 2, 1]")},
+		{`reverse([])`, inspected("[]")},
+		{`flatten([[1, 2], [3], [], 4])`, inspected("[1, 2, 3, 4]")},
+		{`slice([1, 2, 3, 4], 1, 3)`, inspected("[2, 3]")},
+		{`slice([1, 2, 3, 4], 2)`, inspected("[3, 4]")},
+		{`slice([1, 2, 3], 2, 5)`, "slice range [2:5] out of bounds for array of length 3"},
+		{`flatten([[1, 2], [3]])`, inspected("[1, 2, 3]")},
+		{`flatten([[[1]], [[2, 3]]])`, inspected("[[1], [2, 3]]")},
+		{`flatten([[[1]], [[2, 3]]], 2)`, inspected("[1, 2, 3]")},
+		{`flatten([[1]], -1)`, "depth passed to `flatten` cannot be negative, got -1"},
+		{`zip([1, 2, 3], ["a", "b"])`, inspected("[[1, a], [2, b]]")},
+		{`zip([1], [2], [3])`, inspected("[[1, 2, 3]]")},
+		{`zip([1])`, "wrong number of arguments to `zip`, expect at least 2, got=1"},
+		{`range(4)`, inspected("[0, 1, 2, 3]")},
+		{`range(2, 5)`, inspected("[2, 3, 4]")},
+		{`range(10, 0, -3)`, inspected("[10, 7, 4, 1]")},
+		{`range(5, 2)`, inspected("[]")},
+		{`range(0, 5, 0)`, "step passed to `range` cannot be 0"},
+		{`range(10 ** 12)`, "`range` would make 1000000000000 elements, the most is 16777216"},
+		{`range(-9223372036854775807 - 1, 9223372036854775807)`, "`range` would make 18446744073709551615 elements, the most is 16777216"},
+		{`range(0, 9223372036854775807, 4611686018427387904)`, inspected("[0, 4611686018427387904]")},
+		{`range(5, -9223372036854775807 - 1, -9223372036854775807 - 1)`, inspected("[5, -9223372036854775803]")},
+		{`len(range(16777216))`, 16777216},
+		{`append([1, 2], 3)`, inspected("[1, 2, 3]")},
+		{`append([1], "a", [2, 3])`, inspected("[1, a, [2, 3]]")},
+		{`append([], 1.5)`, inspected("[1.5]")},
+		{`propose a = [1]; append(a, 2); a`, inspected("[1]")},
+		{`append([int: 1], 2, 3)`, inspected("[1, 2, 3]")},
+		{`append([int: 1], 2.5)`, "Appending error: cannot append FLOAT to an array of INTEGER"},
+		{`append([1])`, "wrong number of arguments to `append`, expect at least 2, got=1"},
+		{`append(1, 2)`, "argument 1 to `append` must be ARRAY, got INTEGER"},
+		{`concat([1, 2], [], ["a"])`, inspected("[1, 2, a]")},
+		{`concat([[1]], [[2]])`, inspected("[[1], [2]]")},
+		{`concat([string: "a"], [1])`, "Appending error: cannot append INTEGER to an array of STRING"},
+		{`append(concat([int: 1], [2]), "x")`, "Appending error: cannot append STRING to an array of INTEGER"},
+		{`append(filter([int: 1, 2], func(x) { x > 1 }), "x")`, "Appending error: cannot append STRING to an array of INTEGER"},
+		{`concat([1], 2)`, "argument 2 to `concat` must be ARRAY, got INTEGER"},
+		{`contains([1, 2, 3], 2)`, true},
+		{`contains([1, "a", 2.5], "a")`, true},
+		{`contains([[1, 2], [3]], [3])`, true},
+		{`contains([1, 2, 3], 4)`, false},
+		{`indexOf([1, 2, 3], 3)`, 2},
+		{`indexOf([1, 2, 3], 4)`, -1},
+		{`indexOf(1, 4)`, "argument 1 to `indexOf` must be STRING or ARRAY, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		if expected, ok := tt.expected.(inspected); ok {
+			testInspected(t, tt.input, eval, string(expected))
+			continue
+		}
+		testBuiltinResult(t, tt.input, eval, tt.expected)
+	}
+}
+
+func TestArrayBuiltinCallbackError(t *testing.T) {
+	input := `
+propose fail = func(x) { x + y };
+map([1, 2], fail);`
+
+	eval := testEval(input)
+	errObj, ok := eval.(*object.Error)
+	if !ok {
+		t.Fatalf("object is not Error, got=%T (%+v)", eval, eval)
+	}
+	if errObj.Message != "identifier not found: y" {
+		t.Errorf("wrong error message. got=%s", errObj.Message)
+	}
+	if errObj.Line != 2 || errObj.Column != 30 {
+		t.Errorf("Error position: expect=2:30, got=%d:%d", errObj.Line, errObj.Column)
+	}
+}
+
+func testInspected(t *testing.T, input string, eval object.Object, expected string) {
+	if errObj, ok := eval.(*object.Error); ok {
+		t.Errorf("%s: unexpected error %s", input, errObj.Message)
+		return
+	}
+	if eval.Inspect() != expected {
+		t.Errorf("%s: Inspect error: expect=%s, got=%s", input, expected, eval.Inspect())
+	}
+}
@@ -0,0 +1,145 @@
+package evaluator
+
+import "yap/object"
+
+var hashBuiltins = map[string]*object.Builtin{
+	// has(hash, key) tells whether key is in hash, has(set, x) whether x is in set
+	"has": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("has", args, 2, 2); err != nil {
+				return err
+			}
+			if set, ok := args[0].(*object.Set); ok {
+				key, err := setElementArg("has", args, 1)
+				if err != nil {
+					return err
+				}
+				return nativeBoolToBooleanObject(set.Has(key))
+			}
+			hash, key, err := hashAndKey("has", args)
+			if err != nil {
+				return err
+			}
+			_, ok := hash.Pairs[key.HashKey()]
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+
+	// delete(hash, key) removes key from hash and tells whether it was there
+	"delete": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("delete", args, 2, 2); err != nil {
+				return err
+			}
+			hash, key, err := hashAndKey("delete", args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(hash.Delete(key))
+		},
+	},
+
+	// get(hash, key, default?) returns the value of key, or default (null) when it is missing
+	"get": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("get", args, 2, 3); err != nil {
+				return err
+			}
+			hash, key, err := hashAndKey("get", args)
+			if err != nil {
+				return err
+			}
+			if pair, ok := hash.Pairs[key.HashKey()]; ok {
+				return pair.Value
+			}
+			if len(args) == 3 {
+				return args[2]
+			}
+			return NULL
+		},
+	},
+
+	// merge(a, b, ...) returns a new hash with the pairs of every hash,
+	// when a key shows up more than once the last value wins
+	"merge": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("wrong number of arguments to `merge`, expect at least 1, got=0")
+			}
+			merged := object.NewHash()
+			for i, arg := range args {
+				hash, ok := arg.(*object.Hash)
+				if !ok {
+					return argTypeError("merge", i, object.HASH_OBJ, arg)
+				}
+				for _, pair := range hash.OrderedPairs() {
+					merged.Set(pair.Key.(object.Hashable), pair.Value)
+				}
+			}
+			return merged
+		},
+	},
+
+	// entries(hash) returns the pairs of hash as [key, value] arrays
+	"entries": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("entries", args, 1, 1); err != nil {
+				return err
+			}
+			hash, ok := args[0].(*object.Hash)
+			if !ok {
+				return argTypeError("entries", 0, object.HASH_OBJ, args[0])
+			}
+			entries := []object.Object{}
+			for _, pair := range hash.OrderedPairs() {
+				entries = append(entries, &object.Array{Elements: []object.Object{pair.Key, pair.Value}})
+			}
+			return &object.Array{Elements: entries}
+		},
+	},
+
+	// fromEntries(array) is the opposite of entries
+	"fromEntries": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("fromEntries", args, 1, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("fromEntries", 0, object.ARRAY_OBJ, args[0])
+			}
+			hash := object.NewHash()
+			for i, element := range arr.Elements {
+				entry, ok := element.(*object.Array)
+				if !ok || len(entry.Elements) != 2 {
+					return newError("entry %d passed to `fromEntries` must be a [key, value] array, got %s",
+						i, element.Inspect())
+				}
+				key, ok := object.AsHashable(entry.Elements[0])
+				if !ok {
+					return newError("unusable as hash key: %s", entry.Elements[0].Type())
+				}
+				hash.Set(key, entry.Elements[1])
+			}
+			return hash
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range hashBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+func hashAndKey(name string, args []object.Object) (*object.Hash, object.Hashable, *object.Error) {
+	hash, ok := args[0].(*object.Hash)
+	if !ok {
+		return nil, nil, argTypeError(name, 0, object.HASH_OBJ, args[0])
+	}
+	key, ok := object.AsHashable(args[1])
+	if !ok {
+		return nil, nil, newError("unusable as hash key: %s", args[1].Type())
+	}
+	return hash, key, nil
+}
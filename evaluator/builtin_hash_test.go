@@ -0,0 +1,65 @@
+package evaluator
+
+import "testing"
+
+func TestHashBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`has({"a": 1}, "a")`, true},
+		{`has({"a": 1}, "b")`, false},
+		{`has({1: 1}, 1)`, true},
+		{`has([1], 1)`, "argument 1 to `has` must be HASH, got ARRAY"},
+		{`has({"a": 1}, [1])`, "unusable as hash key: ARRAY"},
+		{`propose h = {"a": 1, "b": 2}; delete(h, "a")`, true},
+		{`propose h = {"a": 1, "b": 2}; delete(h, "c")`, false},
+		{`propose h = {"a": 1, "b": 2}; delete(h, "a"); h`, inspected("{b: 2}")},
+		{`propose h = {"a": 1, "b": 2, "c": 3}; delete(h, "b"); keys(h)`, inspected("[a, c]")},
+		{`get({"a": 1}, "a")`, 1},
+		{`get({"a": 1}, "b")`, nil},
+		{`get({"a": 1}, "b", 0)`, 0},
+		{`get({"a": 1})`, "wrong number of arguments to `get`, expect=2 to 3, got=1"},
+		{`merge({"a": 1, "b": 2}, {"b": 3, "c": 4})`, inspected("{a: 1, b: 3, c: 4}")},
+		{`merge({"a": 1}, {}, {"a": 2})`, inspected("{a: 2}")},
+		{`propose h = {"a": 1}; merge(h, {"b": 2}); h`, inspected("{a: 1}")},
+		{`merge({"a": 1}, [1])`, "argument 2 to `merge` must be HASH, got ARRAY"},
+		{`merge()`, "wrong number of arguments to `merge`, expect at least 1, got=0"},
+		{`entries({"b": 1, "a": 2})`, inspected("[[b, 1], [a, 2]]")},
+		{`entries({})`, inspected("[]")},
+		{`fromEntries([["b", 1], ["a", 2]])`, inspected("{b: 1, a: 2}")},
+		{`fromEntries(entries({"x": 1, "y": 2}))`, inspected("{x: 1, y: 2}")},
+		{`fromEntries([["a", 1], ["a", 2]])`, inspected("{a: 2}")},
+		{`fromEntries([[1, 2, 3]])`, "entry 0 passed to `fromEntries` must be a [key, value] array, got [1, 2, 3]"},
+		{`fromEntries([[[1], 2]])`, "unusable as hash key: ARRAY"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		if expected, ok := tt.expected.(inspected); ok {
+			testInspected(t, tt.input, eval, string(expected))
+			continue
+		}
+		testBuiltinResult(t, tt.input, eval, tt.expected)
+	}
+}
+
+func TestHashOrder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"z": 1, "a": 2, "m": 3, "b": 4, "y": 5}`, "{z: 1, a: 2, m: 3, b: 4, y: 5}"},
+		{`keys({"z": 1, "a": 2, "m": 3, "b": 4, "y": 5})`, "[z, a, m, b, y]"},
+		{`values({"z": 1, "a": 2, "m": 3, "b": 4, "y": 5})`, "[1, 2, 3, 4, 5]"},
+		{`{"a": 1, "b": 2, "a": 3}`, "{a: 3, b: 2}"},
+		{`keys({"a": 1, "b": 2, "a": 3})`, "[a, b]"},
+	}
+
+	for _, tt := range tests {
+		// maps are randomized on every range, so a few rounds catch a lost order
+		for i := 0; i < 10; i++ {
+			testInspected(t, tt.input, testEval(tt.input), tt.expected)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package evaluator
+
+import (
+	"strings"
+	"unicode/utf8"
+	"yap/object"
+)
+
+// maxBuiltStringLength bounds the strings repeat and the pad builtins make, so a
+// typo like repeat("ab", 10 ** 12) does not eat all the memory
+const maxBuiltStringLength = 1 << 24
+
+var stringBuiltins = map[string]*object.Builtin{
+	// split(str, separator?), without a separator the string is split around whitespace
+	"split": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("split", args, 1, 2); err != nil {
+				return err
+			}
+			str, err := stringArg("split", args, 0)
+			if err != nil {
+				return err
+			}
+
+			var parts []string
+			if len(args) == 1 {
+				parts = strings.Fields(str)
+			} else {
+				sep, err := stringArg("split", args, 1)
+				if err != nil {
+					return err
+				}
+				parts = strings.Split(str, sep)
+			}
+			return stringsToArray(parts)
+		},
+	},
+
+	// join(array, separator?)
+	"join": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("join", args, 1, 2); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("join", 0, object.ARRAY_OBJ, args[0])
+			}
+			sep := ""
+			if len(args) == 2 {
+				s, err := stringArg("join", args, 1)
+				if err != nil {
+					return err
+				}
+				sep = s
+			}
+
+			parts := []string{}
+			for i, element := range arr.Elements {
+				str, ok := element.(*object.String)
+				if !ok {
+					return newError("element %d passed to `join` must be STRING, got %s", i, element.Type())
+				}
+				parts = append(parts, str.Value)
+			}
+			return &object.String{Value: strings.Join(parts, sep)}
+		},
+	},
+
+	"trim":      trimBuiltin("trim", strings.Trim, strings.TrimSpace),
+	"trimLeft":  trimBuiltin("trimLeft", strings.TrimLeft, trimLeftSpace),
+	"trimRight": trimBuiltin("trimRight", strings.TrimRight, trimRightSpace),
+
+	"upper": stringMapBuiltin("upper", strings.ToUpper),
+	"lower": stringMapBuiltin("lower", strings.ToLower),
+
+	"startsWith": stringTestBuiltin("startsWith", strings.HasPrefix),
+	"endsWith":   stringTestBuiltin("endsWith", strings.HasSuffix),
+
+	// replace(str, old, new) only replaces the first match, replaceAll replaces every one of them
+	"replace":    replaceBuiltin("replace", 1),
+	"replaceAll": replaceBuiltin("replaceAll", -1),
+
+	// substring(str, start, end?) returns the characters str[start:end], end defaults to the length of str
+	"substring": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("substring", args, 2, 3); err != nil {
+				return err
+			}
+			str, err := stringArg("substring", args, 0)
+			if err != nil {
+				return err
+			}
+			return substringOf("substring", str, args)
+		},
+	},
+
+	// repeat(str, count)
+	"repeat": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("repeat", args, 2, 2); err != nil {
+				return err
+			}
+			str, err := stringArg("repeat", args, 0)
+			if err != nil {
+				return err
+			}
+			count, err := intArg("repeat", args, 1)
+			if err != nil {
+				return err
+			}
+			if count < 0 {
+				return newError("count passed to `repeat` cannot be negative, got %d", count)
+			}
+			if len(str) > 0 && count > maxBuiltStringLength/int64(len(str)) {
+				return newError("`repeat` would make a string longer than %d bytes", maxBuiltStringLength)
+			}
+			return &object.String{Value: strings.Repeat(str, int(count))}
+		},
+	},
+
+	"padLeft":  padBuiltin("padLeft", true),
+	"padRight": padBuiltin("padRight", false),
+
+	// ord(char) returns the character code of a one character string
+	"ord": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("ord", args, 1, 1); err != nil {
+				return err
+			}
+			str, err := stringArg("ord", args, 0)
+			if err != nil {
+				return err
+			}
+			if utf8.RuneCountInString(str) != 1 {
+				return newError("argument to `ord` must be a single character, got %q", str)
+			}
+			r, _ := utf8.DecodeRuneInString(str)
+			return &object.Integer{Value: int64(r)}
+		},
+	},
+
+	// chr(code) is the opposite of ord
+	"chr": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("chr", args, 1, 1); err != nil {
+				return err
+			}
+			code, err := intArg("chr", args, 0)
+			if err != nil {
+				return err
+			}
+			if code < 0 || code > utf8.MaxRune || !utf8.ValidRune(rune(code)) {
+				return newError("%d is not a valid character code", code)
+			}
+			return &object.String{Value: string(rune(code))}
+		},
+	},
+
+	// bytes(str) returns the UTF-8 bytes of str, where len and indexing work on characters
+	"bytes": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("bytes", args, 1, 1); err != nil {
+				return err
+			}
+			str, err := stringArg("bytes", args, 0)
+			if err != nil {
+				return err
+			}
+			elements := make([]object.Object, len(str))
+			for i := 0; i < len(str); i++ {
+				elements[i] = &object.Integer{Value: int64(str[i])}
+			}
+			return &object.Array{Elements: elements}
+		},
+	},
+
+	// byteLen(str) is the number of bytes of str, len(str) counts its characters
+	"byteLen": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("byteLen", args, 1, 1); err != nil {
+				return err
+			}
+			str, err := stringArg("byteLen", args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(len(str))}
+		},
+	},
+
+	// fromBytes(array) is the opposite of bytes, the bytes have to be valid UTF-8
+	"fromBytes": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("fromBytes", args, 1, 1); err != nil {
+				return err
+			}
+			arr, ok := args[0].(*object.Array)
+			if !ok {
+				return argTypeError("fromBytes", 0, object.ARRAY_OBJ, args[0])
+			}
+			bytes := make([]byte, len(arr.Elements))
+			for i, element := range arr.Elements {
+				b, ok := element.(*object.Integer)
+				if !ok || b.Value < 0 || b.Value > 255 {
+					return newError("element %d passed to `fromBytes` must be a byte from 0 to 255, got %s",
+						i, element.Inspect())
+				}
+				bytes[i] = byte(b.Value)
+			}
+			if !utf8.Valid(bytes) {
+				return newError("bytes passed to `fromBytes` are not valid UTF-8")
+			}
+			return &object.String{Value: string(bytes)}
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range stringBuiltins {
+		builtins[name] = builtin
+	}
+}
+
+func trimLeftSpace(s string) string {
+	return strings.TrimLeft(s, " \t\n\r")
+}
+
+func trimRightSpace(s string) string {
+	return strings.TrimRight(s, " \t\n\r")
+}
+
+// trimBuiltin makes trim(str, chars?), without chars whitespace is removed
+func trimBuiltin(name string, trim func(string, string) string, trimSpace func(string) string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 1, 2); err != nil {
+				return err
+			}
+			strs, err := stringArgs(name, args)
+			if err != nil {
+				return err
+			}
+			if len(strs) == 1 {
+				return &object.String{Value: trimSpace(strs[0])}
+			}
+			return &object.String{Value: trim(strs[0], strs[1])}
+		},
+	}
+}
+
+func stringMapBuiltin(name string, fn func(string) string) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 1, 1); err != nil {
+				return err
+			}
+			str, err := stringArg(name, args, 0)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: fn(str)}
+		},
+	}
+}
+
+func stringTestBuiltin(name string, fn func(string, string) bool) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 2, 2); err != nil {
+				return err
+			}
+			strs, err := stringArgs(name, args)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(fn(strs[0], strs[1]))
+		},
+	}
+}
+
+func replaceBuiltin(name string, count int) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 3, 3); err != nil {
+				return err
+			}
+			strs, err := stringArgs(name, args)
+			if err != nil {
+				return err
+			}
+			return &object.String{Value: strings.Replace(strs[0], strs[1], strs[2], count)}
+		},
+	}
+}
+
+// padBuiltin makes pad(str, width, padding?), padding defaults to a space and
+// is repeated until str is width long
+func padBuiltin(name string, left bool) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount(name, args, 2, 3); err != nil {
+				return err
+			}
+			str, err := stringArg(name, args, 0)
+			if err != nil {
+				return err
+			}
+			width, err := intArg(name, args, 1)
+			if err != nil {
+				return err
+			}
+			if width > maxBuiltStringLength {
+				return newError("width passed to `%s` cannot be more than %d, got %d", name, maxBuiltStringLength, width)
+			}
+			padding := " "
+			if len(args) == 3 {
+				padding, err = stringArg(name, args, 2)
+				if err != nil {
+					return err
+				}
+				if padding == "" {
+					return newError("padding passed to `%s` cannot be empty", name)
+				}
+			}
+
+			missing := int(width) - utf8.RuneCountInString(str)
+			if missing <= 0 {
+				return &object.String{Value: str}
+			}
+			fill := string([]rune(strings.Repeat(padding, missing/utf8.RuneCountInString(padding)+1))[:missing])
+			if left {
+				return &object.String{Value: fill + str}
+			}
+			return &object.String{Value: str + fill}
+		},
+	}
+}
+
+// substringOf returns the characters of str from args[1] up to args[2], which
+// defaults to the length of str
+func substringOf(name string, str string, args []object.Object) object.Object {
+	runes := []rune(str)
+	start, err := intArg(name, args, 1)
+	if err != nil {
+		return err
+	}
+	end := int64(len(runes))
+	if len(args) == 3 {
+		end, err = intArg(name, args, 2)
+		if err != nil {
+			return err
+		}
+	}
+
+	if start < 0 || end > int64(len(runes)) || start > end {
+		return newError("%s range [%d:%d] out of bounds for string of length %d",
+			name, start, end, len(runes))
+	}
+	return &object.String{Value: string(runes[start:end])}
+}
+
+func stringsToArray(strs []string) *object.Array {
+	elements := []object.Object{}
+	for _, s := range strs {
+		elements = append(elements, &object.String{Value: s})
+	}
+	return &object.Array{Elements: elements}
+}
+
+func checkArgCount(name string, args []object.Object, min, max int) *object.Error {
+	if len(args) >= min && len(args) <= max {
+		return nil
+	}
+	if min == max {
+		return newError("wrong number of arguments to `%s`, expect=%d, got=%d", name, min, len(args))
+	}
+	return newError("wrong number of arguments to `%s`, expect=%d to %d, got=%d", name, min, max, len(args))
+}
+
+func argTypeError(name string, idx int, expected object.ObjectType, got object.Object) *object.Error {
+	return newError("argument %d to `%s` must be %s, got %s", idx+1, name, expected, got.Type())
+}
+
+func stringArg(name string, args []object.Object, idx int) (string, *object.Error) {
+	str, ok := args[idx].(*object.String)
+	if !ok {
+		return "", argTypeError(name, idx, object.STRING_OBJ, args[idx])
+	}
+	return str.Value, nil
+}
+
+func stringArgs(name string, args []object.Object) ([]string, *object.Error) {
+	strs := []string{}
+	for i := range args {
+		str, err := stringArg(name, args, i)
+		if err != nil {
+			return nil, err
+		}
+		strs = append(strs, str)
+	}
+	return strs, nil
+}
+
+func intArg(name string, args []object.Object, idx int) (int64, *object.Error) {
+	num, ok := args[idx].(*object.Integer)
+	if !ok {
+		return 0, argTypeError(name, idx, object.INTEGER_OBJ, args[idx])
+	}
+	return num.Value, nil
+}
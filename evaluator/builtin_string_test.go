@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`split("a,b,,c", ",")`, []string{"a", "b", "", "c"}},
+		{`split("  hello   big world ")`, []string{"hello", "big", "world"}},
+		{`split("abc", "")`, []string{"a", "b", "c"}},
+		{`split(5)`, "argument 1 to `split` must be STRING, got INTEGER"},
+		{`split("a", ",", "b")`, "wrong number of arguments to `split`, expect=1 to 2, got=3"},
+		{`join(["a", "b", "c"], "-")`, "a-b-c"},
+		{`join(["a", "b"])`, "ab"},
+		{`join(split("1 2 3"), "+")`, "1+2+3"},
+		{`join([1, 2], ",")`, "element 0 passed to `join` must be STRING, got INTEGER"},
+		{`join("ab", ",")`, "argument 1 to `join` must be ARRAY, got STRING"},
+		{`trim("  hi \n")`, "hi"},
+		{`trim("xxhixx", "x")`, "hi"},
+		{`trimLeft("  hi  ")`, "hi  "},
+		{`trimRight("  hi  ")`, "  hi"},
+		{`trimRight("hi!?!", "!?")`, "hi"},
+		{`trim(1)`, "argument 1 to `trim` must be STRING, got INTEGER"},
+		{`upper("Hello")`, "HELLO"},
+		{`lower("Hello")`, "hello"},
+		{`upper()`, "wrong number of arguments to `upper`, expect=1, got=0"},
+		{`contains("hello", "ell")`, true},
+		{`contains("hello", "xyz")`, false},
+		{`startsWith("hello", "he")`, true},
+		{`startsWith("hello", "lo")`, false},
+		{`endsWith("hello", "lo")`, true},
+		{`endsWith("hello", 1)`, "argument 2 to `endsWith` must be STRING, got INTEGER"},
+		{`indexOf("hello", "l")`, 2},
+		{`indexOf("hello", "z")`, -1},
+		{`replace("a-b-c", "-", "+")`, "a+b-c"},
+		{`replaceAll("a-b-c", "-", "+")`, "a+b+c"},
+		{`replace("abc", "x", "y")`, "abc"},
+		{`replaceAll("abc", "b")`, "wrong number of arguments to `replaceAll`, expect=3, got=2"},
+		{`substring("hello", 1, 3)`, "el"},
+		{`substring("hello", 2)`, "llo"},
+		{`substring("hello", 5)`, ""},
+		{`substring("hello", 3, 2)`, "substring range [3:2] out of bounds for string of length 5"},
+		{`substring("hello", 0, 6)`, "substring range [0:6] out of bounds for string of length 5"},
+		{`substring("hello", "1")`, "argument 2 to `substring` must be INTEGER, got STRING"},
+		{`repeat("ab", 3)`, "ababab"},
+		{`repeat("ab", 0)`, ""},
+		{`repeat("ab", -1)`, "count passed to `repeat` cannot be negative, got -1"},
+		{`repeat("ab", 9223372036854775807)`, "`repeat` would make a string longer than 16777216 bytes"},
+		{`repeat("ab", 8388609)`, "`repeat` would make a string longer than 16777216 bytes"},
+		{`len(repeat("ab", 8388608))`, 16777216},
+		{`repeat("", 9223372036854775807)`, ""},
+		{`padLeft("7", 3, "0")`, "007"},
+		{`padLeft("7", 3)`, "  7"},
+		{`padRight("ab", 7, "xy")`, "abxyxyx"},
+		{`padRight("abcdef", 3)`, "abcdef"},
+		{`padLeft("a", 3, "")`, "padding passed to `padLeft` cannot be empty"},
+		{`padLeft("a", 9223372036854775807)`, "width passed to `padLeft` cannot be more than 16777216, got 9223372036854775807"},
+		{`padRight("a", 16777217, "x")`, "width passed to `padRight` cannot be more than 16777216, got 16777217"},
+		{`len(padRight("a", 16777216, "xy"))`, 16777216},
+		{`ord("A")`, 65},
+		{`ord("ab")`, "argument to `ord` must be a single character, got \"ab\""},
+		{`chr(97)`, "a"},
+		{`chr(ord("a") + 1)`, "b"},
+		{`chr(-1)`, "-1 is not a valid character code"},
+	}
+
+	for _, test := range tests {
+		testBuiltinResult(t, test.input, testEval(test.input), test.expected)
+	}
+}
+
+// testBuiltinResult checks eval against expected, where a string that is not
+// the expected value of a STRING result is the expected error message
+func testBuiltinResult(t *testing.T, input string, eval object.Object, expected interface{}) {
+	switch expected := expected.(type) {
+	case int:
+		testIntegerObject(t, eval, int64(expected))
+	case bool:
+		testBooleanObject(t, eval, expected)
+	case nil:
+		testNullObject(t, eval)
+	case []string:
+		arr, ok := eval.(*object.Array)
+		if !ok {
+			t.Errorf("%s: object is not Array, got=%T (%+v)", input, eval, eval)
+			return
+		}
+		if len(arr.Elements) != len(expected) {
+			t.Errorf("%s: Array length error: expect=%d, got=%d", input, len(expected), len(arr.Elements))
+			return
+		}
+		for i, str := range expected {
+			testStringObject(t, arr.Elements[i], str)
+		}
+	case string:
+		if errObj, ok := eval.(*object.Error); ok {
+			if errObj.Message != expected {
+				t.Errorf("%s: wrong error message. expect=%s, got=%s", input, expected, errObj.Message)
+			}
+			return
+		}
+		testStringObject(t, eval, expected)
+	default:
+		t.Fatalf("%s: unhandled expected type %T", input, expected)
+	}
+}
+
+func TestUnicodeStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`len("héllo")`, 5},
+		{`len("日本語")`, 3},
+		{`"héllo"[1]`, "é"},
+		{`"日本語"[2]`, "語"},
+		{`"日本語"[3]`, nil},
+		{`"abc"[-1]`, nil},
+		{`substring("héllo wörld", 6, 11)`, "wörld"},
+		{`slice("日本語", 1)`, "本語"},
+		{`slice("日本語", 1, 4)`, "slice range [1:4] out of bounds for string of length 3"},
+		{`slice(1, 0)`, "argument 1 to `slice` must be ARRAY or STRING, got INTEGER"},
+		{`indexOf("日本語", "語")`, 2},
+		{`padLeft("é", 3, "·")`, "··é"},
+		{`padRight("ab", 5, "日本")`, "ab日本日"},
+		{`propose out = []; for (c in "héllo") { out = append(out, c); }; out`, []string{"h", "é", "l", "l", "o"}},
+		{`propose café = "crème"; upper(café)`, "CRÈME"},
+		{`byteLen("héllo")`, 6},
+		{`bytes("é")[0]`, 195},
+		{`len(bytes("日本語"))`, 9},
+		{`fromBytes(bytes("héllo"))`, "héllo"},
+		{`fromBytes([104, 105])`, "hi"},
+		{`fromBytes([195])`, "bytes passed to `fromBytes` are not valid UTF-8"},
+		{`fromBytes([256])`, "element 0 passed to `fromBytes` must be a byte from 0 to 255, got 256"},
+		{`fromBytes("hi")`, "argument 1 to `fromBytes` must be ARRAY, got STRING"},
+	}
+
+	for _, test := range tests {
+		testBuiltinResult(t, test.input, testEval(test.input), test.expected)
+	}
+}
@@ -0,0 +1,103 @@
+package evaluator
+
+import (
+	"yap/ast"
+	"yap/object"
+	"yap/token"
+)
+
+const DEFAULT_MAX_CALL_DEPTH = 10000
+
+// MaxCallDepth is how deep calls that are not tail calls can nest before
+// an error stops them, well before the Go stack would overflow
+var MaxCallDepth = DEFAULT_MAX_CALL_DEPTH
+
+// callDepth is how many function calls are running right now
+var callDepth = 0
+
+// evalCall evaluates the function and the arguments of a call, tok is
+// where errors of the call itself are reported
+func evalCall(node *ast.CallExpression, env *object.Enviroment) (object.Object, []object.Object, map[string]object.Object, token.Token, object.Object) {
+	tok := node.Token
+	if ident, ok := node.Function.(*ast.Identifier); ok {
+		tok = ident.Token
+	}
+
+	function := Eval(node.Function, env)
+	if isError(function) {
+		return nil, nil, nil, tok, function
+	}
+
+	args := evalExpressions(node.Arguments, env)
+	if len(args) == 1 && isError(args[0]) {
+		return nil, nil, nil, tok, args[0]
+	}
+	named := map[string]object.Object{}
+	for _, arg := range node.NamedArguments {
+		if _, ok := named[arg.Name.Value]; ok {
+			return nil, nil, nil, tok,
+				withPosition(newError("argument `%s` is given more than once", arg.Name.Value), arg.Name.Token)
+		}
+		value := Eval(arg.Value, env)
+		if isError(value) {
+			return nil, nil, nil, tok, value
+		}
+		named[arg.Name.Value] = value
+	}
+
+	if _, ok := function.(*object.Builtin); ok && len(named) > 0 {
+		return nil, nil, nil, tok, withPosition(newError("builtin functions do not take named arguments"), tok)
+	}
+	return function, args, named, tok, nil
+}
+
+// evalTailCall evaluates `sayless f(...)`. Calling a function is left to the caller
+// through a ReturnValue, builtins are called right away since they cannot recurse
+func evalTailCall(node *ast.CallExpression, env *object.Enviroment) object.Object {
+	function, args, named, tok, err := evalCall(node, env)
+	if err != nil {
+		return err
+	}
+	fn, ok := function.(*object.Function)
+	if !ok {
+		val := withPosition(applyFunction(function, args), tok)
+		if isError(val) {
+			return val
+		}
+		return &object.ReturnValue{Value: val}
+	}
+	return &object.ReturnValue{Tail: &object.TailCall{Function: fn, Args: args, Named: named, Token: tok}}
+}
+
+var callBuiltins = map[string]*object.Builtin{
+	// maxCallDepth(n?) sets how deep calls that are not tail calls can nest,
+	// it returns the previous limit
+	"maxCallDepth": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("maxCallDepth", args, 0, 1); err != nil {
+				return err
+			}
+			previous := &object.Integer{Value: int64(MaxCallDepth)}
+			if len(args) == 1 {
+				depth, err := intArg("maxCallDepth", args, 0)
+				if err != nil {
+					return err
+				}
+				if depth < 1 || depth > maxCallDepthLimit {
+					return newError("call depth must be between 1 and %d, got %d", maxCallDepthLimit, depth)
+				}
+				MaxCallDepth = int(depth)
+			}
+			return previous
+		},
+	},
+}
+
+// maxCallDepthLimit keeps maxCallDepth from allowing enough nesting to overflow the Go stack
+const maxCallDepthLimit = 100000
+
+func init() {
+	for name, builtin := range callBuiltins {
+		builtins[name] = builtin
+	}
+}
@@ -0,0 +1,68 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestTailCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`func count(n) { perhaps (n == 0) { sayless "done"; }; sayless count(n - 1); };
+count(1000000)`, "done"},
+		{`func sum(n, acc = 0) { perhaps (n == 0) { sayless acc; }; sayless sum(n - 1, acc = acc + n); };
+sum(1000000)`, "500000500000"},
+		{`func isEven(n) { perhaps (n == 0) { sayless true; }; sayless isOdd(n - 1); };
+func isOdd(n) { perhaps (n == 0) { sayless false; }; sayless isEven(n - 1); };
+isEven(100001)`, "false"},
+		{`func walk(list, i = 0, total = 0) {
+  perhaps (i == len(list)) { sayless total; };
+  sayless walk(list, i + 1, total + list[i]);
+};
+walk(range(100000))`, "4999950000"},
+		{`func loop(n) { for (propose i = 0; i < 1; ++i) { perhaps (n > 0) { sayless loop(n - 1); } }; n };
+loop(50000)`, "0"},
+		{`func f(x) { sayless len(x); }; f([1, 2])`, "2"},
+		{`func f(...xs) { sayless xs; }; func g() { sayless f(1, 2); }; g()`, "[1, 2]"},
+		{`func id(x) { x }; sayless id(5);`, "5"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCallDepth(t *testing.T) {
+	input := `func deep(n) { perhaps (n == 0) { sayless 0; }; sayless 1 + deep(n - 1); };`
+	testInspected(t, input+"deep(5000)", testEval(input+"deep(5000)"), "5000")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input + "deep(20000)", "maximum call depth of 10000 exceeded"},
+		{input + "maxCallDepth(100); deep(100)", "maximum call depth of 100 exceeded"},
+		{"maxCallDepth(0)", "call depth must be between 1 and 100000, got 0"},
+		{`func f(a) { sayless g(a, 2); }; func g(a) { a }; f(1)`, "too many arguments to func g(a): expect at most 1, got 2"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+
+	testInspected(t, input+"maxCallDepth(50); maxCallDepth()", testEval(input+"maxCallDepth(50); maxCallDepth()"), "50")
+	testInspected(t, input+"deep(300)", testEval(input+"deep(300)"), "300")
+	if callDepth != 0 {
+		t.Errorf("call depth was not unwound, got=%d", callDepth)
+	}
+}
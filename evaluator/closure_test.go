@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestLoopClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`propose fns = [];
+for (propose i = 0; i < 3; ++i) { fns = append(fns, func() { i }); }
+map(fns, func(f) { f() })`, "[0, 1, 2]"},
+		{`propose fns = [];
+for (x in ["a", "b"]) { fns = append(fns, func() { x }); }
+map(fns, func(f) { f() })`, "[a, b]"},
+		{`propose fns = [];
+for (propose i = 0; i < 3; ++i) { propose sq = i * i; fns = append(fns, func() { sq }); }
+map(fns, func(f) { f() })`, "[0, 1, 4]"},
+		{`propose fns = [];
+for (propose i = 0; i < 3; ++i) { fns = append(fns, func() { i }); i = i + 1; }
+map(fns, func(f) { f() })`, "[1, 3]"},
+		{`propose total = 0;
+for (propose i = 1; i < 5; ++i) { total = total + i; }
+total`, "10"},
+		{`propose n = 0; for (n < 5) { n = n + 1; }; n`, "5"},
+		{`propose i = 100; for (propose i = 0; i < 3; ++i) { i; }; i`, "100"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestClosures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// counters keep their own state
+		{`func counter() { propose n = 0; func() { n = n + 1; n } }
+propose a = counter(); propose b = counter();
+a(); a(); b();
+[a(), b()]`, "[3, 2]"},
+		{`func counter(start = 0, step = 1) {
+  propose n = start;
+  propose inc = func() { n = n + step; n };
+  propose get = func() { n };
+  {"inc": inc, "get": get}
+}
+propose c = counter(10, step = 5);
+propose inc = c["inc"]; propose get = c["get"];
+inc(); inc();
+get()`, "20"},
+		// memoization helpers
+		{`func memoize(f) {
+  propose cache = {};
+  propose calls = 0;
+  propose memo = func(n) {
+    perhaps (has(cache, n)) { sayless cache[n]; }
+    calls = calls + 1;
+    propose result = f(n);
+    cache = merge(cache, {n: result});
+    result
+  };
+  {"call": memo, "calls": func() { calls }}
+}
+propose m = memoize(func(n) { n * n });
+propose call = m["call"]; propose calls = m["calls"];
+[call(4), call(4), call(5), calls()]`, "[16, 16, 25, 2]"},
+		{`propose cache = {0: 0, 1: 1};
+func fib(n) {
+  perhaps (has(cache, n)) { sayless cache[n]; }
+  propose result = fib(n - 1) + fib(n - 2);
+  cache = merge(cache, {n: result});
+  result
+}
+fib(80)`, "23416728348467685"},
+		// callback factories
+		{`func multiplier(k) { func(x) { x * k } }
+map([1, 2, 3], multiplier(10))`, "[10, 20, 30]"},
+		{`func adders(...ks) { map(ks, func(k) { func(x) { x + k } }) }
+propose fs = adders(1, 2, 3);
+map(fs, func(f) { f(10) })`, "[11, 12, 13]"},
+		{`func compose(f, g) { func(x) { f(g(x)) } }
+propose inc = func(x) { x + 1 };
+propose double = func(x) { x * 2 };
+propose h = compose(inc, double);
+h(5)`, "11"},
+		{`func on(events) {
+  propose log = [];
+  propose handlers = map(events, func(e) { func() { log = append(log, e); len(log) } });
+  for (h in handlers) { h(); }
+  log
+}
+on(["open", "close"])`, "[open, close]"},
+		// recursive inner functions
+		{`func outer() {
+  func fact(n) { perhaps (n < 2) { 1 } otherwise { n * fact(n - 1) } }
+  fact(10)
+}
+outer()`, "3628800"},
+		{`func outer() {
+  func isEven(n) { perhaps (n == 0) { true } otherwise { isOdd(n - 1) } };
+  func isOdd(n) { perhaps (n == 0) { false } otherwise { isEven(n - 1) } };
+  [isEven(10), isOdd(7)]
+}
+outer()`, "[true, true]"},
+		{`map([3, 4], func fact(n) { perhaps (n < 2) { 1 } otherwise { n * fact(n - 1) } })`, "[6, 24]"},
+		{`propose fact = 5; func outer() { func fact(n) { n }; fact(1) }; outer(); fact`, "5"},
+		// shadowing
+		{`propose x = 1; func f(x) { x * 10 }; [f(2), x]`, "[20, 1]"},
+		{`propose x = 1; func f() { propose x = 2; x }; [f(), x]`, "[2, 1]"},
+		{`propose x = 1; func f() { x = 2; x }; [f(), x]`, "[2, 2]"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestClosureErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`map([1], func fact(n) { n }); fact(1)`, "identifier not found: fact"},
+		{`propose n = 0; func f() { n = "x" }; f()`,
+			"type mismatch error: could not set STRING into 'n' variable (Type = INTEGER)"},
+		{`func f() { missing = 1 }; f()`, "valariable missing does not exist, (perhaps not yet declare?)"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
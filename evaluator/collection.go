@@ -0,0 +1,248 @@
+package evaluator
+
+import (
+	"yap/ast"
+	"yap/object"
+)
+
+func evalSetLiteral(node *ast.SetLiteral, env *object.Enviroment) object.Object {
+	set := object.NewSet()
+	for _, elementNode := range node.Elements {
+		element := Eval(elementNode, env)
+		if isError(element) {
+			return element
+		}
+		key, ok := object.AsHashable(element)
+		if !ok {
+			return withPosition(newError("unusable as set element: %s", element.Type()), node.Token)
+		}
+		set.Add(key)
+	}
+	return set
+}
+
+// evalForInExpression runs the body once per element, each time in a new
+// enviroment so the loop variable never leaks out or clobbers an outer one
+func evalForInExpression(node *ast.ForInExpression, env *object.Enviroment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isError(iterable) {
+		return iterable
+	}
+	elements, err := iterate(iterable)
+	if err != nil {
+		return withPosition(err, node.Token)
+	}
+
+	for _, element := range elements {
+		loopEnv := object.NewEncloseEnviroment(env)
+		loopEnv.Declare(node.Variable.Value, element)
+
+		result := Eval(node.Statements, loopEnv)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+	}
+	return nil
+}
+
+// iterate returns what a for-in loop walks over: the elements of an array,
+// a tuple or a set, the keys of a hash and the characters of a string
+func iterate(obj object.Object) ([]object.Object, *object.Error) {
+	switch obj := obj.(type) {
+	case *object.Array:
+		return append([]object.Object{}, obj.Elements...), nil
+	case *object.Tuple:
+		return obj.Elements, nil
+	case *object.Set:
+		return append([]object.Object{}, obj.Order...), nil
+	case *object.Hash:
+		return append([]object.Object{}, obj.Keys...), nil
+	case *object.String:
+		chars := []object.Object{}
+		for _, ch := range obj.Value {
+			chars = append(chars, &object.String{Value: string(ch)})
+		}
+		return chars, nil
+	default:
+		return nil, newError("cannot iterate over %s", typeOf(obj))
+	}
+}
+
+// setArg returns args[idx] as a set
+func setArg(name string, args []object.Object, idx int) (*object.Set, *object.Error) {
+	set, ok := args[idx].(*object.Set)
+	if !ok {
+		return nil, argTypeError(name, idx, object.SET_OBJ, args[idx])
+	}
+	return set, nil
+}
+
+func setElementArg(name string, args []object.Object, idx int) (object.Hashable, *object.Error) {
+	key, ok := object.AsHashable(args[idx])
+	if !ok {
+		return nil, newError("unusable as set element: %s", args[idx].Type())
+	}
+	return key, nil
+}
+
+// setOperation builds the set of the elements of the first set for which keep
+// is true, then adds the elements of the others when addOthers is set
+func setOperation(name string, keep func(object.Hashable, []*object.Set) bool, addOthers bool) *object.Builtin {
+	return &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 2 {
+				return newError("wrong number of arguments to `%s`, expect at least 2, got=%d", name, len(args))
+			}
+			sets := make([]*object.Set, len(args))
+			for i := range args {
+				set, err := setArg(name, args, i)
+				if err != nil {
+					return err
+				}
+				sets[i] = set
+			}
+
+			result := object.NewSet()
+			for _, element := range sets[0].Order {
+				if key := element.(object.Hashable); keep(key, sets[1:]) {
+					result.Add(key)
+				}
+			}
+			if addOthers {
+				for _, set := range sets[1:] {
+					for _, element := range set.Order {
+						result.Add(element.(object.Hashable))
+					}
+				}
+			}
+			return result
+		},
+	}
+}
+
+var collectionBuiltins = map[string]*object.Builtin{
+	// set(x?) returns a set of the elements of an array, a tuple, a set, the keys of
+	// a hash or the characters of a string, set() is the empty set
+	"set": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("set", args, 0, 1); err != nil {
+				return err
+			}
+			set := object.NewSet()
+			if len(args) == 0 {
+				return set
+			}
+			elements, err := iterate(args[0])
+			if err != nil {
+				return err
+			}
+			for _, element := range elements {
+				key, ok := object.AsHashable(element)
+				if !ok {
+					return newError("unusable as set element: %s", element.Type())
+				}
+				set.Add(key)
+			}
+			return set
+		},
+	},
+
+	// tuple(x) returns a tuple of what a for-in loop over x would give
+	"tuple": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("tuple", args, 0, 1); err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				return &object.Tuple{}
+			}
+			elements, err := iterate(args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Tuple{Elements: elements}
+		},
+	},
+
+	// array(x) returns an array of what a for-in loop over x would give
+	"array": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("array", args, 1, 1); err != nil {
+				return err
+			}
+			elements, err := iterate(args[0])
+			if err != nil {
+				return err
+			}
+			return &object.Array{Elements: append([]object.Object{}, elements...)}
+		},
+	},
+
+	// add(set, x) adds x to set and tells whether it was missing
+	"add": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("add", args, 2, 2); err != nil {
+				return err
+			}
+			set, err := setArg("add", args, 0)
+			if err != nil {
+				return err
+			}
+			key, err := setElementArg("add", args, 1)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(set.Add(key))
+		},
+	},
+
+	// remove(set, x) removes x from set and tells whether it was there
+	"remove": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("remove", args, 2, 2); err != nil {
+				return err
+			}
+			set, err := setArg("remove", args, 0)
+			if err != nil {
+				return err
+			}
+			key, err := setElementArg("remove", args, 1)
+			if err != nil {
+				return err
+			}
+			return nativeBoolToBooleanObject(set.Remove(key))
+		},
+	},
+
+	// union(a, b, ...) has the elements found in any of the sets
+	"union": setOperation("union", func(object.Hashable, []*object.Set) bool { return true }, true),
+
+	// intersection(a, b, ...) has the elements of a found in every other set
+	"intersection": setOperation("intersection", func(key object.Hashable, others []*object.Set) bool {
+		for _, set := range others {
+			if !set.Has(key) {
+				return false
+			}
+		}
+		return true
+	}, false),
+
+	// difference(a, b, ...) has the elements of a found in none of the other sets
+	"difference": setOperation("difference", func(key object.Hashable, others []*object.Set) bool {
+		for _, set := range others {
+			if set.Has(key) {
+				return false
+			}
+		}
+		return true
+	}, false),
+}
+
+func init() {
+	for name, builtin := range collectionBuiltins {
+		builtins[name] = builtin
+	}
+}
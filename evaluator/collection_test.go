@@ -0,0 +1,133 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestSets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{3, 1, 2, 1}", "{3, 1, 2}"},
+		{"{1, 1.0, 1.00d}", "{1}"},
+		{"set()", "set()"},
+		{"set([2, 1, 2])", "{2, 1}"},
+		{`set("abca")`, "{a, b, c}"},
+		{`set({"x": 1, "y": 2})`, "{x, y}"},
+		{"len({1, 2, 2})", "2"},
+		{"has({1, 2}, 2)", "true"},
+		{"has({1, 2}, 3)", "false"},
+		{"has({(1, 2)}, (1, 2))", "true"},
+		{"propose s = {1}; add(s, 2)", "true"},
+		{"propose s = {1}; add(s, 1)", "false"},
+		{"propose s = {1}; add(s, 2); add(s, 0); s", "{1, 2, 0}"},
+		{"propose s = {1, 2, 3}; remove(s, 2); s", "{1, 3}"},
+		{"propose s = {1, 2, 3}; remove(s, 5)", "false"},
+		{"union({1, 2}, {2, 3}, {4})", "{1, 2, 3, 4}"},
+		{"intersection({1, 2, 3}, {3, 2, 4})", "{2, 3}"},
+		{"intersection({1, 2, 3}, {2, 3}, {3})", "{3}"},
+		{"difference({1, 2, 3}, {2}, {3})", "{1}"},
+		{"difference({1}, {1})", "set()"},
+		{"{1, 2} == {2, 1}", "true"},
+		{"{1, 2} == {1, 2, 3}", "false"},
+		{"{1, 2} == [1, 2]", "false"},
+		{"set() == set()", "true"},
+		{"{} == set()", "false"},
+		{"[set: {1}, set()]", "[{1}, set()]"},
+		{"propose s = {1}; s = {2}; s", "{2}"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTuples(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(1, \"a\", 2.5)", "(1, a, 2.5)"},
+		{"(1,)", "(1,)"},
+		{"()", "()"},
+		{"(1)", "1"},
+		{"(1, (2, 3))[1][0]", "2"},
+		{"(1, 2)[2]", "null"},
+		{"len((1, 2, 3))", "3"},
+		{"tuple([1, 2])", "(1, 2)"},
+		{"tuple()", "()"},
+		{"array((1, 2))", "[1, 2]"},
+		{"(1, 2) == (1, 2)", "true"},
+		{"(1, 2) == (1.0, 2.0)", "true"},
+		{"(1, 2) == [1, 2]", "false"},
+		{"(1, 2) < (1, 3)", "true"},
+		{"(1, 2) > (1,)", "true"},
+		{"sort([(2, 1), (1, 5), (1, 2)])", "[(1, 2), (1, 5), (2, 1)]"},
+		{"{(0, 0): \"origin\", (0, 1): \"up\"}[(0, 1)]", "up"},
+		{"{(1, (2, 3)): \"nested\"}[(1, (2, 3))]", "nested"},
+		{"has({(1, 2): 0}, (2, 1))", "false"},
+		{"has({(1, 2): 0}, freeze([1, 2]))", "false"},
+		{"propose (a, b) = (1, 2); a + b", "3"},
+		{"propose swap = func(p) { (p[1], p[0]) }; propose (x, y) = swap((1, 2)); [x, y]", "[2, 1]"},
+		{"propose (only,) = (\"one\",); only", "one"},
+		{"propose divmod = func(a, b) { (a / b, a % b) }; propose (q, r) = divmod(17, 5); [q, r]", "[3, 2]"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForIn(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose sum = 0; for (x in [1, 2, 3]) { sum = sum + x; }; sum", "6"},
+		{"propose sum = 0; for (x in (1, 2, 3)) { sum = sum + x; }; sum", "6"},
+		{"propose out = []; for (x in {3, 1, 2}) { out = append(out, x); }; out", "[3, 1, 2]"},
+		{`propose out = []; for (k in {"b": 1, "a": 2}) { out = append(out, k); }; out`, "[b, a]"},
+		{`propose out = []; for (c in "abc") { out = append(out, c); }; out`, "[a, b, c]"},
+		{"propose n = 0; for (x in []) { n = n + 1; }; n", "0"},
+		{"propose x = 10; for (x in [1, 2]) { x; }; x", "10"},
+		{"propose f = func() { for (x in [1, 2, 3]) { perhaps (x == 2) { sayless x * 10; } }; 0 }; f()", "20"},
+		{"propose arr = [1, 2]; propose n = 0; for (x in arr) { pop(arr); n = n + 1; }; n", "2"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCollectionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{[1], 2}", "unusable as set element: ARRAY"},
+		{"set([{\"a\": 1}])", "unusable as set element: HASH"},
+		{"add({1}, [2])", "unusable as set element: ARRAY"},
+		{"add([1], 2)", "argument 1 to `add` must be SET, got ARRAY"},
+		{"union({1}, [2])", "argument 2 to `union` must be SET, got ARRAY"},
+		{"union({1})", "wrong number of arguments to `union`, expect at least 2, got=1"},
+		{"{([1], 2): 0}", "unusable hash key TUPLE"},
+		{"for (x in 5) { x; }", "cannot iterate over INTEGER"},
+		{"propose (a, b) = (1, 2, 3);", "cannot destructure a tuple of 3 elements into (a, b), it has too many"},
+		{"propose (a, b) = [1, 2];", "cannot destructure ARRAY into (a, b), expected a TUPLE"},
+		{"len(5)", "argument to `len` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
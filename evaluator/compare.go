@@ -0,0 +1,183 @@
+package evaluator
+
+import "yap/object"
+
+// visitedPair is a pair of containers already being compared further up,
+// meeting it again means the values are cyclic and that branch is settled
+type visitedPair struct {
+	left, right object.Object
+}
+
+// objectsEqual compares two objects by value: numbers compare numerically,
+// arrays and tuples element by element, sets by their elements whatever the order
+// and hashes pair by pair
+func objectsEqual(left, right object.Object) bool {
+	return equal(left, right, map[visitedPair]bool{})
+}
+
+func equal(left, right object.Object, visited map[visitedPair]bool) bool {
+	if left == nil {
+		left = NULL
+	}
+	if right == nil {
+		right = NULL
+	}
+
+	switch l := left.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float, *object.Decimal:
+		if !isNumber(right) {
+			return false
+		}
+		if left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ {
+			cmp, err := compareObjects(left, right)
+			return err == nil && cmp == 0
+		}
+		if object.IsInteger(left.Type()) && object.IsInteger(right.Type()) {
+			cmp, _ := compareObjects(left, right)
+			return cmp == 0
+		}
+		return toFloat(left) == toFloat(right)
+	case *object.String:
+		r, ok := right.(*object.String)
+		return ok && l.Value == r.Value
+	case *object.Boolean:
+		r, ok := right.(*object.Boolean)
+		return ok && l.Value == r.Value
+	case *object.Null:
+		return right.Type() == object.NULL_OBJ
+	case *object.Array:
+		r, ok := right.(*object.Array)
+		if !ok || len(l.Elements) != len(r.Elements) {
+			return false
+		}
+		if l == r || visited[visitedPair{l, r}] {
+			return true
+		}
+		visited[visitedPair{l, r}] = true
+		return equalElements(l.Elements, r.Elements, visited)
+	case *object.Tuple:
+		r, ok := right.(*object.Tuple)
+		if !ok || len(l.Elements) != len(r.Elements) {
+			return false
+		}
+		return equalElements(l.Elements, r.Elements, visited)
+	case *object.Set:
+		r, ok := right.(*object.Set)
+		if !ok || len(l.Elements) != len(r.Elements) {
+			return false
+		}
+		for key := range l.Elements {
+			if _, ok := r.Elements[key]; !ok {
+				return false
+			}
+		}
+		return true
+	case *object.Hash:
+		r, ok := right.(*object.Hash)
+		if !ok || len(l.Pairs) != len(r.Pairs) {
+			return false
+		}
+		if l == r || visited[visitedPair{l, r}] {
+			return true
+		}
+		visited[visitedPair{l, r}] = true
+		for key, pair := range l.Pairs {
+			other, ok := r.Pairs[key]
+			if !ok || !equal(pair.Value, other.Value, visited) {
+				return false
+			}
+		}
+		return true
+	default:
+		return left == right
+	}
+}
+
+func equalElements(left, right []object.Object, visited map[visitedPair]bool) bool {
+	for i := range left {
+		if !equal(left[i], right[i], visited) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareObjects orders two numbers, two strings, two arrays or two tuples, returning -1, 0 or 1.
+// Arrays and tuples are ordered element by element, a shorter array comes first when it is a
+// prefix of the longer one. It is shared by `<`, `>` and sort
+func compareObjects(left, right object.Object) (int, *object.Error) {
+	return compare(left, right, map[visitedPair]bool{})
+}
+
+func compare(left, right object.Object, visited map[visitedPair]bool) (int, *object.Error) {
+	switch l := left.(type) {
+	case *object.Integer, *object.BigInteger, *object.Float, *object.Decimal:
+		if !isNumber(right) {
+			break
+		}
+		if left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ {
+			l, lok := toDecimal(left)
+			r, rok := toDecimal(right)
+			if !lok || !rok {
+				break
+			}
+			return l.Cmp(r), nil
+		}
+		if l, ok := left.(*object.Integer); ok {
+			if r, ok := right.(*object.Integer); ok {
+				return compareValues(l.Value, r.Value), nil
+			}
+		}
+		if object.IsInteger(left.Type()) && object.IsInteger(right.Type()) {
+			return toBigInt(left).Cmp(toBigInt(right)), nil
+		}
+		return compareValues(toFloat(left), toFloat(right)), nil
+	case *object.String:
+		if r, ok := right.(*object.String); ok {
+			return compareValues(l.Value, r.Value), nil
+		}
+	case *object.Array:
+		r, ok := right.(*object.Array)
+		if !ok {
+			break
+		}
+		if l == r || visited[visitedPair{l, r}] {
+			return 0, nil
+		}
+		visited[visitedPair{l, r}] = true
+		return compareElements(l.Elements, r.Elements, visited)
+	case *object.Tuple:
+		if r, ok := right.(*object.Tuple); ok {
+			return compareElements(l.Elements, r.Elements, visited)
+		}
+	}
+	return 0, newError("cannot compare %s and %s", typeOf(left), typeOf(right))
+}
+
+func compareElements(left, right []object.Object, visited map[visitedPair]bool) (int, *object.Error) {
+	for i := 0; i < len(left) && i < len(right); i++ {
+		cmp, err := compare(left[i], right[i], visited)
+		if err != nil || cmp != 0 {
+			return cmp, err
+		}
+	}
+	return compareValues(int64(len(left)), int64(len(right))), nil
+}
+
+func compareValues[T int64 | float64 | string](left, right T) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func typeOf(obj object.Object) object.ObjectType {
+	if obj == nil {
+		return object.NULL_OBJ
+	}
+	return obj.Type()
+}
@@ -0,0 +1,80 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestStructuralComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc" == "abc"`, true},
+		{`"abc" == "abd"`, false},
+		{`"abc" != "abd"`, true},
+		{`"abc" < "abd"`, true},
+		{`"b" > "abc"`, true},
+		{`"ab" < "abc"`, true},
+		{`"" < "a"`, true},
+		{`"1" == 1`, false},
+		{`"1" != 1`, true},
+		{`[1, 2, 3] == [1, 2, 3]`, true},
+		{`[1, 2, 3] == [1, 2]`, false},
+		{`[1, 2.0] == [1.0, 2]`, true},
+		{`[[1, "a"], []] == [[1, "a"], []]`, true},
+		{`[[1, "a"]] != [[1, "b"]]`, true},
+		{`[1, 2] < [1, 3]`, true},
+		{`[1, 2] < [1, 2, 0]`, true},
+		{`[2] > [1, 9]`, true},
+		{`[] < [[]]`, true},
+		{`[["a", 2]] < [["a", 10]]`, true},
+		{`{"a": 1, "b": [2]} == {"b": [2], "a": 1}`, true},
+		{`{"a": 1} == {"a": 2}`, false},
+		{`{"a": 1} == {"b": 1}`, false},
+		{`{"a": {"b": [1]}} == {"a": {"b": [1]}}`, true},
+		{`[1] == {"a": 1}`, false},
+		{`[1] == 1`, false},
+		{`propose a = [1]; a == a`, true},
+		{`propose f = func() { 1 }; f == f`, true},
+		{`func() { 1 } == func() { 1 }`, false},
+		{`[1] < ["a"]`, "cannot compare INTEGER and STRING"},
+		{`[1] < "a"`, "type mismatch: ARRAY < STRING"},
+		{`{"a": 1} < {"a": 2}`, "unknown operator: HASH < HASH"},
+	}
+
+	for _, tt := range tests {
+		testBuiltinResult(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestCyclicComparison(t *testing.T) {
+	one := &object.Integer{Value: 1}
+	left := &object.Array{Elements: []object.Object{one}}
+	left.Elements = append(left.Elements, left)
+	right := &object.Array{Elements: []object.Object{one}}
+	right.Elements = append(right.Elements, right)
+
+	if !objectsEqual(left, right) {
+		t.Errorf("cyclic arrays with the same shape are not equal")
+	}
+	if cmp, err := compareObjects(left, right); err != nil || cmp != 0 {
+		t.Errorf("cyclic arrays compare error: expect=0, got=%d (%v)", cmp, err)
+	}
+
+	hash := object.NewHash()
+	key := &object.String{Value: "self"}
+	hash.Set(key, hash)
+	other := object.NewHash()
+	other.Set(key, other)
+
+	if !objectsEqual(hash, other) {
+		t.Errorf("cyclic hashes with the same shape are not equal")
+	}
+
+	different := &object.Array{Elements: []object.Object{&object.Integer{Value: 2}}}
+	different.Elements = append(different.Elements, different)
+	if objectsEqual(left, different) {
+		t.Errorf("cyclic arrays with different elements are equal")
+	}
+}
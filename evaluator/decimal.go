@@ -0,0 +1,256 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"yap/object"
+)
+
+const (
+	DEFAULT_DECIMAL_PRECISION = 16
+	// maxDecimalPrecision keeps a typo like decimalPrecision(1000000000) from eating the memory
+	maxDecimalPrecision = 1000
+)
+
+// decimalContext holds how decimal division and rounding behave, it is changed
+// with the decimalPrecision and decimalRounding builtins
+var decimalContext = struct {
+	precision int32
+	rounding  object.RoundingMode
+}{
+	precision: DEFAULT_DECIMAL_PRECISION,
+	rounding:  object.ROUND_HALF_EVEN,
+}
+
+func resetDecimalContext() {
+	decimalContext.precision = DEFAULT_DECIMAL_PRECISION
+	decimalContext.rounding = object.ROUND_HALF_EVEN
+}
+
+// evalDecimalInfixExpression works on a Decimal and another Decimal or an integer,
+// which is promoted first. Floats are refused since they would bring back the
+// rounding errors decimals are meant to avoid
+func evalDecimalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	if left.Type() == object.FLOAT_OBJ || right.Type() == object.FLOAT_OBJ {
+		return newError("cannot mix %s and %s in %s %s %s, convert one of them with decimal() or float()",
+			left.Type(), right.Type(), left.Type(), operator, right.Type())
+	}
+	if operator == "**" {
+		return evalDecimalPower(left, right)
+	}
+
+	l_val, _ := toDecimal(left)
+	r_val, _ := toDecimal(right)
+	switch operator {
+	case "+":
+		return l_val.Add(r_val)
+	case "-":
+		return l_val.Sub(r_val)
+	case "*":
+		return l_val.Mul(r_val)
+	case "/":
+		if r_val.Sign() == 0 {
+			return newError("zero division error: %s / 0", l_val.Inspect())
+		}
+		return l_val.Quo(r_val, decimalContext.precision, decimalContext.rounding)
+	case "%":
+		if r_val.Sign() == 0 {
+			return newError("zero division error: %s %% 0", l_val.Inspect())
+		}
+		return l_val.Rem(r_val)
+	case "<":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) != 0)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalDecimalPower only takes integer exponents, a negative one divides like `/` does
+func evalDecimalPower(left, right object.Object) object.Object {
+	base, ok := toDecimal(left)
+	exp, isInt := right.(*object.Integer)
+	if !ok || !isInt {
+		return newError("exponent of a decimal must be an INTEGER, got %s", right.Type())
+	}
+
+	if exp.Value == math.MinInt64 {
+		return newError("decimal overflow: %s ** %d is too large", base.Inspect(), exp.Value)
+	}
+	n := exp.Value
+	if n < 0 {
+		n = -n
+	}
+	// both checks divide, multiplying by a huge n could overflow
+	bits, scale := int64(base.Coef.BitLen()), int64(base.Scale)
+	if bits > 0 && n > maxBigIntegerBits/bits || scale > 0 && n > math.MaxInt32/scale {
+		return newError("decimal overflow: %s ** %d is too large", base.Inspect(), exp.Value)
+	}
+	result := &object.Decimal{
+		Coef:  new(big.Int).Exp(base.Coef, big.NewInt(n), nil),
+		Scale: base.Scale * int32(n),
+	}
+	if exp.Value >= 0 {
+		return result
+	}
+	if result.Sign() == 0 {
+		return newError("zero division error: %s ** %d", base.Inspect(), exp.Value)
+	}
+	one := object.NewDecimalFromInt(big.NewInt(1))
+	return one.Quo(result, decimalContext.precision, decimalContext.rounding)
+}
+
+// toDecimal converts integers and decimals, everything else reports false
+func toDecimal(obj object.Object) (*object.Decimal, bool) {
+	switch obj := obj.(type) {
+	case *object.Decimal:
+		return obj, true
+	case *object.Integer, *object.BigInteger:
+		return object.NewDecimalFromInt(toBigInt(obj)), true
+	default:
+		return nil, false
+	}
+}
+
+func roundingModeArg(name string, args []object.Object, idx int) (object.RoundingMode, *object.Error) {
+	str, err := stringArg(name, args, idx)
+	if err != nil {
+		return "", err
+	}
+	for _, mode := range object.RoundingModes {
+		if string(mode) == str {
+			return mode, nil
+		}
+	}
+	return "", newError("unknown rounding mode %q passed to `%s`", str, name)
+}
+
+var decimalBuiltins = map[string]*object.Builtin{
+	// decimal(x) converts an int, a float or a string like "19.99" into a decimal.
+	// A float is converted from its shortest text, so decimal(0.1) is 0.1
+	"decimal": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("decimal", args, 1, 1); err != nil {
+				return err
+			}
+			switch arg := args[0].(type) {
+			case *object.Decimal, *object.Integer, *object.BigInteger:
+				d, _ := toDecimal(arg)
+				return d
+			case *object.Float:
+				d, ok := object.ParseDecimal(strconv.FormatFloat(arg.Value, 'f', -1, 64))
+				if !ok {
+					return newError("Cannot convert %g into a Decimal", arg.Value)
+				}
+				return d
+			case *object.String:
+				d, ok := object.ParseDecimal(arg.Value)
+				if !ok {
+					return newError("Cannot convert %s into a Decimal", arg.Value)
+				}
+				return d
+			default:
+				return newError("Cannot convert %s into a Decimal", arg.Type())
+			}
+		},
+	},
+
+	// round(x, places?, mode?) rounds a decimal or a float to places digits after
+	// the point (0 by default), with the rounding mode of decimalRounding by default
+	"round": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("round", args, 1, 3); err != nil {
+				return err
+			}
+			places := int64(0)
+			if len(args) > 1 {
+				p, err := intArg("round", args, 1)
+				if err != nil {
+					return err
+				}
+				if p < -maxDecimalPrecision || p > maxDecimalPrecision {
+					return newError("places passed to `round` must be between %d and %d, got %d",
+						-maxDecimalPrecision, maxDecimalPrecision, p)
+				}
+				places = p
+			}
+			mode := decimalContext.rounding
+			if len(args) == 3 {
+				m, err := roundingModeArg("round", args, 2)
+				if err != nil {
+					return err
+				}
+				mode = m
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Decimal:
+				return arg.Round(int32(places), mode)
+			case *object.Integer, *object.BigInteger:
+				d, _ := toDecimal(arg)
+				return normalizeBigInt(d.Round(int32(places), mode).Int())
+			case *object.Float:
+				d, ok := object.ParseDecimal(strconv.FormatFloat(arg.Value, 'f', -1, 64))
+				if !ok {
+					return arg
+				}
+				return &object.Float{Value: d.Round(int32(places), mode).Float64()}
+			default:
+				return newError("argument 1 to `round` must be a number, got %s", arg.Type())
+			}
+		},
+	},
+
+	// decimalPrecision(digits?) sets how many digits after the point a decimal division keeps,
+	// it returns the previous setting
+	"decimalPrecision": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("decimalPrecision", args, 0, 1); err != nil {
+				return err
+			}
+			previous := &object.Integer{Value: int64(decimalContext.precision)}
+			if len(args) == 1 {
+				digits, err := intArg("decimalPrecision", args, 0)
+				if err != nil {
+					return err
+				}
+				if digits < 0 || digits > maxDecimalPrecision {
+					return newError("decimal precision must be between 0 and %d, got %d", maxDecimalPrecision, digits)
+				}
+				decimalContext.precision = int32(digits)
+			}
+			return previous
+		},
+	},
+
+	// decimalRounding(mode?) sets the rounding mode used by decimal division and round,
+	// it returns the previous setting
+	"decimalRounding": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if err := checkArgCount("decimalRounding", args, 0, 1); err != nil {
+				return err
+			}
+			previous := &object.String{Value: string(decimalContext.rounding)}
+			if len(args) == 1 {
+				mode, err := roundingModeArg("decimalRounding", args, 0)
+				if err != nil {
+					return err
+				}
+				decimalContext.rounding = mode
+			}
+			return previous
+		},
+	},
+}
+
+func init() {
+	for name, builtin := range decimalBuiltins {
+		builtins[name] = builtin
+	}
+}
@@ -0,0 +1,138 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+	"yap/object"
+)
+
+func TestDecimalArithmetic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0.1d + 0.2d", "0.3"},
+		{"0.1d + 0.2d == 0.3d", "true"},
+		{"19.99d * 3", "59.97"},
+		{"1.50d", "1.50"},
+		{"1.50d + 1", "2.50"},
+		{"2 - 0.75d", "1.25"},
+		{"1.25d * 1.25d", "1.5625"},
+		{"-1.5d", "-1.5"},
+		{"0.05d", "0.05"},
+		{"10d", "10"},
+		{"10.00d / 4", "2.50"},
+		{"1d / 4d", "0.25"},
+		{"1d / 3d", "0.3333333333333333"},
+		{"2d / 3d", "0.6666666666666667"},
+		{"10.5d % 3", "1.5"},
+		{"-7.5d % 2", "-1.5"},
+		{"1.1d ** 2", "1.21"},
+		{"2d ** -2", "0.25"},
+		{"(2 ** 64) + 0.5d", "18446744073709551616.5"},
+		{"1.50d == 1.5d", "true"},
+		{"1.50d == 1", "false"},
+		{"1.00d == 1", "true"},
+		{"1.5d > 1", "true"},
+		{"0.1d < 0.11d", "true"},
+		{"[1.5d, 2] == [1.50d, 2.0d]", "true"},
+		{"sort([2.5d, 1, 0.75d])", "[0.75, 1, 2.5]"},
+		{"{1.5d: \"a\"}[1.50d]", "a"},
+		{"[decimal: 1.5d, 2d]", "[1.5, 2]"},
+		{"propose price = 9.99d; price = price * 2; price", "19.98"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"round(2.345d, 2)", "2.34"},
+		{"round(2.355d, 2)", "2.36"},
+		{"round(2.345d, 2, \"half_up\")", "2.35"},
+		{"round(2.345d, 2, \"half_down\")", "2.34"},
+		{"round(2.341d, 2, \"up\")", "2.35"},
+		{"round(2.349d, 2, \"down\")", "2.34"},
+		{"round(-2.341d, 2, \"ceiling\")", "-2.34"},
+		{"round(-2.341d, 2, \"floor\")", "-2.35"},
+		{"round(-2.5d)", "-2"},
+		{"round(-2.5d, 0, \"half_up\")", "-3"},
+		{"round(1234.5d, -2)", "1200"},
+		{"round(1.5d, 3)", "1.5"},
+		{"round(2.5)", "2"},
+		{"round(2.675, 2, \"half_up\")", "2.68"},
+		{"round(1250, -2)", "1200"},
+		{"decimalPrecision()", "16"},
+		{"decimalPrecision(2); 2d / 3d", "0.67"},
+		{"decimalPrecision(2); decimalRounding(\"down\"); 2d / 3d", "0.66"},
+		{"decimalRounding(\"up\"); decimalRounding()", "up"},
+		{"decimalPrecision(2); decimalPrecision(4)", "2"},
+		{"decimalRounding(\"half_even\"); decimalPrecision(16); 1d / 3d", "0.3333333333333333"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDecimalConversions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"decimal(0.1) + decimal(0.2)", "0.3"},
+		{"decimal(\"19.99\")", "19.99"},
+		{"decimal(\"-0.5\")", "-0.5"},
+		{"decimal(3)", "3"},
+		{"decimal(2 ** 70)", "1180591620717411303424"},
+		{"float(1.25d)", "1.25"},
+		{"int(19.99d)", "19"},
+		{"int(-19.99d)", "-19"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDecimalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5d + 1.5", "cannot mix DECIMAL and FLOAT in DECIMAL + FLOAT, convert one of them with decimal() or float()"},
+		{"0.5 * 2d", "cannot mix FLOAT and DECIMAL in FLOAT * DECIMAL, convert one of them with decimal() or float()"},
+		{"1.5d == 1.5", "cannot mix DECIMAL and FLOAT in DECIMAL == FLOAT, convert one of them with decimal() or float()"},
+		{"1.5d / 0", "zero division error: 1.5 / 0"},
+		{"1.5d % 0d", "zero division error: 1.5 % 0"},
+		{"1.5d ** 0.5d", "exponent of a decimal must be an INTEGER, got DECIMAL"},
+		{"2.0d ** -9223372036854775808", "decimal overflow: 2.0 ** -9223372036854775808 is too large"},
+		{"1024d ** 1152921504606846976", "decimal overflow: 1024 ** 1152921504606846976 is too large"},
+		{"0." + strings.Repeat("0", 199) + "1d ** 16000000",
+			"decimal overflow: 0." + strings.Repeat("0", 199) + "1 ** 16000000 is too large"},
+		{"1.5d + \"a\"", "type mismatch: DECIMAL + STRING"},
+		{"decimal(\"1.2.3\")", "Cannot convert 1.2.3 into a Decimal"},
+		{"decimal(\"abc\")", "Cannot convert abc into a Decimal"},
+		{"round(1.5d, 0, \"sideways\")", "unknown rounding mode \"sideways\" passed to `round`"},
+		{"decimalPrecision(-1)", "decimal precision must be between 0 and 1000, got -1"},
+		{"sort([1.5d, 1.5])", "cannot `sort` without a comparator: cannot compare FLOAT and DECIMAL"},
+		{"propose a = 1; a = 1.5d", "type mismatch error: could not set DECIMAL into 'a' variable (Type = INTEGER)"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package evaluator
+
+import (
+	"yap/ast"
+	"yap/object"
+	"yap/token"
+)
+
+// destructure binds the names of pattern to the parts of value, it returns
+// nil or the error telling how value does not have the shape of pattern. The
+// names are bound in a scope of their own first, so a value that does not match
+// part way through leaves env as it was
+func destructure(pattern *ast.Pattern, value object.Object, env *object.Enviroment) object.Object {
+	scope := object.NewEncloseEnviroment(env)
+	if err := bindPattern(pattern, value, scope); err != nil {
+		return err
+	}
+
+	for _, name := range patternNames(pattern, nil) {
+		val, _ := scope.Local(name)
+		env.Declare(name, val)
+	}
+	return nil
+}
+
+// patternNames appends every name pattern binds to names, nested patterns included
+func patternNames(pattern *ast.Pattern, names []string) []string {
+	for _, element := range pattern.Elements {
+		if nested, ok := element.Target.(*ast.Pattern); ok {
+			names = patternNames(nested, names)
+		} else {
+			names = append(names, element.Target.(*ast.Identifier).Value)
+		}
+	}
+	if pattern.Rest != nil {
+		names = append(names, pattern.Rest.Value)
+	}
+	return names
+}
+
+func bindPattern(pattern *ast.Pattern, value object.Object, env *object.Enviroment) object.Object {
+	switch pattern.Token.Type {
+	case token.LBRACKET:
+		arr, ok := value.(*object.Array)
+		if !ok {
+			return newError("cannot destructure %s into %s, expected an ARRAY", typeOf(value), pattern.String())
+		}
+		return destructureSequence(pattern, "an array", arr.Elements, env)
+	case token.LPAREN:
+		tuple, ok := value.(*object.Tuple)
+		if !ok {
+			return newError("cannot destructure %s into %s, expected a TUPLE", typeOf(value), pattern.String())
+		}
+		return destructureSequence(pattern, "a tuple", tuple.Elements, env)
+	default:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return newError("cannot destructure %s into %s, expected a HASH", typeOf(value), pattern.String())
+		}
+		return destructureHash(pattern, hash, env)
+	}
+}
+
+func destructureSequence(pattern *ast.Pattern, kind string, elements []object.Object, env *object.Enviroment) object.Object {
+	if len(elements) > len(pattern.Elements) && pattern.Rest == nil {
+		return newError("cannot destructure %s of %d elements into %s, it has too many",
+			kind, len(elements), pattern.String())
+	}
+
+	for i, element := range pattern.Elements {
+		var value object.Object
+		if i < len(elements) {
+			value = elements[i]
+		} else if element.Default != nil {
+			value = Eval(element.Default, env)
+			if isError(value) {
+				return value
+			}
+		} else {
+			return newError("cannot destructure %s of %d elements into %s, it has too few",
+				kind, len(elements), pattern.String())
+		}
+		if err := bindPatternTarget(element.Target, value, env); err != nil {
+			return err
+		}
+	}
+
+	if pattern.Rest != nil {
+		rest := []object.Object{}
+		if len(elements) > len(pattern.Elements) {
+			rest = append(rest, elements[len(pattern.Elements):]...)
+		}
+		env.Declare(pattern.Rest.Value, &object.Array{Elements: rest})
+	}
+	return nil
+}
+
+// destructureHash looks the names of the pattern up as string keys
+func destructureHash(pattern *ast.Pattern, hash *object.Hash, env *object.Enviroment) object.Object {
+	taken := map[object.HashKey]bool{}
+
+	for _, element := range pattern.Elements {
+		key := &object.String{Value: element.Key.Value}
+		taken[key.HashKey()] = true
+
+		var value object.Object
+		if pair, ok := hash.Pairs[key.HashKey()]; ok {
+			value = pair.Value
+		} else if element.Default != nil {
+			value = Eval(element.Default, env)
+			if isError(value) {
+				return value
+			}
+		} else {
+			return newError("cannot destructure into %s, the hash has no key %q",
+				pattern.String(), element.Key.Value)
+		}
+		if err := bindPatternTarget(element.Target, value, env); err != nil {
+			return err
+		}
+	}
+
+	if pattern.Rest != nil {
+		rest := object.NewHash()
+		for _, pair := range hash.OrderedPairs() {
+			key := pair.Key.(object.Hashable)
+			if !taken[key.HashKey()] {
+				rest.Set(key, pair.Value)
+			}
+		}
+		env.Declare(pattern.Rest.Value, rest)
+	}
+	return nil
+}
+
+func bindPatternTarget(target ast.Node, value object.Object, env *object.Enviroment) object.Object {
+	if nested, ok := target.(*ast.Pattern); ok {
+		return bindPattern(nested, value, env)
+	}
+	env.Declare(target.(*ast.Identifier).Value, value)
+	return nil
+}
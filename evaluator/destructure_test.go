@@ -0,0 +1,96 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/lexer"
+	"yap/object"
+	"yap/parser"
+)
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose [a, b] = [1, 2]; [b, a]", "[2, 1]"},
+		{"propose [a, b, ...rest] = [1, 2, 3, 4]; [a, b, rest]", "[1, 2, [3, 4]]"},
+		{"propose [a, ...rest] = [1]; rest", "[]"},
+		{"propose [...all] = [1, 2]; all", "[1, 2]"},
+		{"propose [a, b = 10] = [1]; a + b", "11"},
+		{"propose [a, b = a * 2] = [4]; b", "8"},
+		{"propose [a, b = 10] = [1, 2]; b", "2"},
+		{"propose [[a, b], c] = [[1, 2], 3]; a + b + c", "6"},
+		{"propose [first, (x, y)] = [0, (1, 2)]; [first, x, y]", "[0, 1, 2]"},
+		{"propose [] = []; 1", "1"},
+		{`propose {name, age} = {"name": "Ann", "age": 30}; name + " " + age`, "Ann 30"},
+		{`propose {age, name} = {"name": "Ann", "age": 30}; name`, "Ann"},
+		{`propose {name: who} = {"name": "Ann"}; who`, "Ann"},
+		{`propose {age = 18} = {"name": "Ann"}; age`, "18"},
+		{`propose {name: who = "nobody"} = {}; who`, "nobody"},
+		{`propose {name, ...others} = {"name": "Ann", "age": 30, "city": "Oslo"}; others`, "{age: 30, city: Oslo}"},
+		{`propose {pos: [x, y]} = {"pos": [3, 4]}; x * y`, "12"},
+		{`propose {address: {city}} = {"address": {"city": "Oslo"}}; city`, "Oslo"},
+		{`propose [{name}, {name: other}] = [{"name": "a"}, {"name": "b"}]; name + other`, "ab"},
+		{"propose (q, r) = (7, 2); q - r", "5"},
+		{"propose (head, ...tail) = (1, 2, 3); tail", "[2, 3]"},
+		{"propose pair = func() { [1, 2] }; propose [a, b] = pair(); a + b", "3"},
+		{"propose f = func(p) { propose [a, b] = p; a * b }; f([3, 4])", "12"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose [a, b] = [1];", "cannot destructure an array of 1 elements into [a, b], it has too few"},
+		{"propose [a, b] = [1, 2, 3];", "cannot destructure an array of 3 elements into [a, b], it has too many"},
+		{"propose [a, b] = (1, 2);", "cannot destructure TUPLE into [a, b], expected an ARRAY"},
+		{"propose [a, b] = 5;", "cannot destructure INTEGER into [a, b], expected an ARRAY"},
+		{`propose {name, age} = {"name": "Ann"};`, "cannot destructure into {name, age}, the hash has no key \"age\""},
+		{"propose {name} = [1];", "cannot destructure ARRAY into {name}, expected a HASH"},
+		{"propose [[a, b]] = [[1]];", "cannot destructure an array of 1 elements into [a, b], it has too few"},
+		{"propose [a, [b]] = [1, 2];", "cannot destructure INTEGER into [b], expected an ARRAY"},
+		{"propose [a = x] = [];", "identifier not found: x"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestDestructuringErrorBindsNothing(t *testing.T) {
+	tests := []string{
+		"propose [a, [b]] = [1, 2];",
+		"propose [a, b, c] = [1, 2];",
+		`propose {a, b} = {"a": 1};`,
+		"propose [a, b = x] = [1];",
+	}
+
+	for _, input := range tests {
+		env := object.NewEnviroment()
+		Reset()
+		program := parser.New(lexer.New(input)).ParserProgram()
+		if eval := Eval(program, env); !isError(eval) {
+			t.Errorf("%s: expected an error, got=%T (%+v)", input, eval, eval)
+			continue
+		}
+		for _, name := range []string{"a", "b", "c"} {
+			if val, ok := env.Get(name); ok {
+				t.Errorf("%s: %s is bound to %s after the error", input, name, val.Inspect())
+			}
+		}
+	}
+}
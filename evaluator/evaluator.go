@@ -2,11 +2,12 @@ package evaluator
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"strconv"
+	"unicode/utf8"
 	"yap/ast"
 	"yap/object"
+	"yap/token"
 )
 
 var (
@@ -15,30 +16,39 @@ var (
 	FALSE = &object.Boolean{Value: false}
 )
 
+// Reset puts back what a program can change outside of its enviroment, like the
+// decimal precision, the call depth limit or the imported modules, so the next
+// program run in the same process starts fresh
+func Reset() {
+	resetDecimalContext()
+	MaxCallDepth = DEFAULT_MAX_CALL_DEPTH
+	callDepth = 0
+	resetModules()
+}
+
 func Eval(node ast.Node, env *object.Enviroment) object.Object {
 	switch node := node.(type) {
 	case *ast.Program:
 		return evalProgram(node, env)
 	case *ast.FunctionExpression:
-		params := node.Parameters
-		body := node.Body
+		fu := newFunction(node, env)
 		if node.Name != nil {
-			fu := &object.Function{Parameters: params, Env: env, Body: body}
-			env.Set(node.Name.Value, fu)
-		} else {
-			return &object.Function{Parameters: params, Env: env, Body: body}
+			// a named function used as a value can call itself, but its name
+			// does not leak out like the one of a `func name() {}` statement
+			selfEnv := object.NewEncloseEnviroment(env)
+			selfEnv.Declare(node.Name.Value, fu)
+			fu.Env = selfEnv
 		}
+		return fu
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
-			return function
+		function, args, named, tok, err := evalCall(node, env)
+		if err != nil {
+			return err
 		}
-
-		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
-			return args[0]
+		if _, ok := function.(*object.Builtin); ok {
+			return withPosition(applyFunction(function, args), tok)
 		}
-		return applyFunction(function, args)
+		return withPosition(applyFunctionNamed(function, args, named), tok)
 	case *ast.ForExpression:
 		ident := node.Identifier
 		conditions := node.Conditions
@@ -49,44 +59,47 @@ func Eval(node ast.Node, env *object.Enviroment) object.Object {
 			return nil
 		}
 		return returnNode
+	case *ast.ForInExpression:
+		return evalForInExpression(node, env)
 	case *ast.SayStatement:
 		val := Eval(node.Value, env)
 		if isError(val) {
 			return val
 		}
-		env.Set(node.Name.Value, val)
+		if node.Pattern != nil {
+			return withPosition(destructure(node.Pattern, val, env), node.Token)
+		}
+		env.Declare(node.Name.Value, val)
 	case *ast.PotentialStatement:
 		if env.Exist(node.Name.Value) {
 			val := Eval(node.Value, env)
 			if isError(val) {
 				return val
 			}
-			if !env.TypeComp(node.Name.Value, val.Type()) {
-				return newError("type mismatch error: could not set %s into '%s' variable (Type = %s)",
-					val.Type(), node.Name.String(), env.GetType(node.Name.Value).Type())
-			}
-			env.Set(node.Name.Value, val)
-			return val
+			return setVariable(node.Name.Value, val, env)
 		} else {
 			return newError("valariable %s does not exist, (perhaps not yet declare?)", node.Name.String())
 		}
+	case *ast.AssignStatement:
+		return evalAssignStatement(node, env)
 	case *ast.ExpressionStatement:
+		if fn, ok := node.Expression.(*ast.FunctionExpression); ok && fn.Name != nil {
+			evalFunctionDeclaration(fn, env)
+			return nil
+		}
 		return Eval(node.Expression, env)
 	case *ast.ArrayLiteral:
+		return evalArrayLiteral(node, env)
+	case *ast.HashLiteral:
+		return evalHashLiteral(node, env)
+	case *ast.SetLiteral:
+		return evalSetLiteral(node, env)
+	case *ast.TupleLiteral:
 		elements := evalExpressions(node.Elements, env)
 		if len(elements) == 1 && isError(elements[0]) {
 			return elements[0]
 		}
-		objType := elements[0].Type()
-		for i := 1; i < len(elements); i++ {
-			if elements[i].Type() != objType {
-				return newError("Type mismatch, cannot have an array of %s and %s",
-					objType, elements[i].Type())
-			}
-		}
-		return &object.Array{Elements: elements}
-	case *ast.HashLiteral:
-		return evalHashLiteral(node, env)
+		return &object.Tuple{Elements: elements}
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -96,22 +109,34 @@ func Eval(node ast.Node, env *object.Enviroment) object.Object {
 		if isError(index) {
 			return index
 		}
-		return evalIndexExpression(left, index)
+		return withPosition(evalIndexExpression(left, index), node.Token)
 
 	case *ast.Identifier:
-		return evalIdentifier(node, env)
+		return withPosition(evalIdentifier(node, env), node.Token)
 	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInteger{Value: node.Big}
+		}
 		return &object.Integer{Value: node.Value}
 	case *ast.FloatLiteral:
 		return &object.Float{Value: node.Value}
+	case *ast.DecimalLiteral:
+		d, ok := object.ParseDecimal(node.Value)
+		if !ok {
+			return withPosition(newError("Could not parse %q as a decimal", node.Value), node.Token)
+		}
+		return d
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
+		if node.Operator == "++" || node.Operator == "--" {
+			return withPosition(evalIncrementExpression(node.Operator, node.Right, false, env), node.Token)
+		}
 		right := Eval(node.Right, env)
 		if isError(right) {
 			return right
 		}
-		return evalPrefixExpression(node.Operator, right, node.Right.TokenLiteral(), env)
+		return withPosition(evalPrefixExpression(node.Operator, right), node.Token)
 	case *ast.InfixExpression:
 		left := Eval(node.Left, env)
 		if isError(left) {
@@ -121,10 +146,9 @@ func Eval(node ast.Node, env *object.Enviroment) object.Object {
 		if isError(right) {
 			return right
 		}
-		return evalInfixExpression(left, node.Operator, right, env)
+		return withPosition(evalInfixExpression(left, node.Operator, right, env), node.Token)
 	case *ast.PostfixExpression:
-		left := Eval(node.Left, env)
-		return evalPrefixExpression(node.Operator, left, node.Left.TokenLiteral(), env)
+		return withPosition(evalIncrementExpression(node.Operator, node.Left, true, env), node.Token)
 	case *ast.BlockStatement:
 		return evalBlockStatement(node, env)
 	case *ast.IfExpression:
@@ -133,7 +157,19 @@ func Eval(node ast.Node, env *object.Enviroment) object.Object {
 		return evalTernaryExpression(node, env)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Literal}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
+	case *ast.ImportStatement:
+		return evalImportStatement(node, env)
+	case *ast.MemberExpression:
+		return evalMemberExpression(node, env)
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
+		if call, ok := node.ReturnValue.(*ast.CallExpression); ok {
+			return evalTailCall(call, env)
+		}
 		val := Eval(node.ReturnValue, env)
 		if isError(val) {
 			return val
@@ -161,16 +197,14 @@ func nativeBoolToBooleanObject(input bool) *object.Boolean {
 	return FALSE
 }
 
-func evalPrefixExpression(operator string, right object.Object, name string, env *object.Enviroment) object.Object {
+func evalPrefixExpression(operator string, right object.Object) object.Object {
 	switch operator {
 	case "!":
 		return evalBangOperatorExpression(right)
 	case "-":
 		return evalNegativeOperatorExpression(right)
-	case "++":
-		return evalIncrementOperatorExpression(right, env, name)
-	case "--":
-		return evalDecrementOperatorExpression(right, env, name)
+	case "~":
+		return evalBitwiseNotExpression(right)
 	default:
 		return newError("unknown operator: %s%s", operator, right)
 	}
@@ -190,12 +224,13 @@ func evalBangOperatorExpression(obj object.Object) object.Object {
 }
 
 func evalNegativeOperatorExpression(obj object.Object) object.Object {
-	if obj.Type() != object.INTEGER_OBJ && obj.Type() != object.FLOAT_OBJ {
+	if !isNumber(obj) {
 		return newError("unknown operator: -%s", obj.Type())
 	}
-	if obj.Type() == object.INTEGER_OBJ {
-		val := obj.(*object.Integer).Value
-		return &object.Integer{Value: -val}
+	if object.IsInteger(obj.Type()) {
+		return evalNegativeIntegerExpression(obj)
+	} else if d, ok := obj.(*object.Decimal); ok {
+		return d.Neg()
 	} else if obj.Type() == object.FLOAT_OBJ {
 		val := obj.(*object.Float).Value
 		return &object.Float{Value: -val}
@@ -203,41 +238,6 @@ func evalNegativeOperatorExpression(obj object.Object) object.Object {
 	return NULL
 }
 
-func evalInfixIntExpression(left object.Object, operator string,
-	right object.Object, env *object.Enviroment) object.Object {
-	l_val := left.(*object.Integer).Value
-	r_val := right.(*object.Integer).Value
-	switch operator {
-	case "+":
-		return &object.Integer{Value: l_val + r_val}
-	case "-":
-		return &object.Integer{Value: l_val - r_val}
-	case "*":
-		return &object.Integer{Value: (l_val * r_val)}
-	case "%":
-		return &object.Integer{Value: (l_val % r_val)}
-	case "/":
-		if r_val == 0 {
-			log.Fatal("ZERO DIVISION ERROR")
-		}
-		return &object.Integer{Value: l_val / r_val}
-	case "**":
-		val := math.Pow(float64(l_val), float64(r_val))
-		return &object.Integer{Value: int64(val)}
-	case "<":
-		return nativeBoolToBooleanObject(l_val < r_val)
-	case ">":
-		return nativeBoolToBooleanObject(l_val > r_val)
-	case "==":
-		return nativeBoolToBooleanObject(l_val == r_val)
-	case "!=":
-		return nativeBoolToBooleanObject(l_val != r_val)
-	default:
-		return newError("unknown operator: %s %s %s",
-			left.Type(), operator, right.Type())
-	}
-}
-
 func evalInfixFloatExpression(left object.Object, operator string,
 	right object.Object) object.Object {
 	l_val := left.(*object.Float).Value
@@ -253,7 +253,7 @@ func evalInfixFloatExpression(left object.Object, operator string,
 		return NULL
 	case "/":
 		if r_val == 0.0 {
-			log.Fatal("ZERO DIVISION ERROR")
+			return newError("zero division error: %g / 0", l_val)
 		}
 		return &object.Float{Value: float64(l_val / r_val)}
 	case "**":
@@ -312,63 +312,39 @@ func evalStringInfixExpression(left object.Object, operator string,
 	}
 }
 
-func evalIncrementOperatorExpression(obj object.Object, env *object.Enviroment, name string) object.Object {
-	if obj.Type() == object.INTEGER_OBJ {
-		val := obj.(*object.Integer).Value
-		env.Set(name, &object.Integer{Value: val + 1})
-		return &object.Integer{Value: val + 1}
-	} else if obj.Type() == object.FLOAT_OBJ {
-		val := obj.(*object.Float).Value
-		env.Set(name, &object.Float{Value: val + 1.0})
-		return &object.Float{Value: val + 1.0}
-	}
-	return NULL
-}
-
-func evalDecrementOperatorExpression(obj object.Object, env *object.Enviroment, name string) object.Object {
-	log.Println(name)
-	if obj.Type() == object.INTEGER_OBJ {
-		val := obj.(*object.Integer).Value
-		env.Set(name, &object.Integer{Value: val - 1})
-		return &object.Integer{Value: val - 1}
-	} else if obj.Type() == object.FLOAT_OBJ {
-		val := obj.(*object.Float).Value
-		env.Set(name, &object.Float{Value: val - 1.0})
-		return &object.Float{Value: val - 1.0}
-	}
-	return NULL
-}
+var bitwiseOperators = map[string]bool{"&": true, "|": true, "^": true, "<<": true, ">>": true}
 
 func evalInfixExpression(left object.Object, operator string,
 	right object.Object, env *object.Enviroment) object.Object {
 
 	switch {
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
-		return evalInfixIntExpression(left, operator, right, env)
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.FLOAT_OBJ:
-		val := left.(*object.Integer).Value
-		left = &object.Float{Value: float64(val)}
-		return evalInfixFloatExpression(left, operator, right)
-	case left.Type() == object.FLOAT_OBJ && right.Type() == object.INTEGER_OBJ:
-		val := right.(*object.Integer).Value
-		right = &object.Float{Value: float64(val)}
-		return evalInfixFloatExpression(left, operator, right)
-	case left.Type() == object.FLOAT_OBJ && right.Type() == object.FLOAT_OBJ:
+	case object.IsInteger(left.Type()) && object.IsInteger(right.Type()):
+		return evalIntegerInfixExpression(left, operator, right)
+	case bitwiseOperators[operator]:
+		return newError("unknown operator: %s %s %s, bitwise operators only work on ints",
+			left.Type(), operator, right.Type())
+	case (left.Type() == object.DECIMAL_OBJ || right.Type() == object.DECIMAL_OBJ) &&
+		isNumber(left) && isNumber(right):
+		return evalDecimalInfixExpression(left, operator, right)
+	case isNumber(left) && isNumber(right):
+		left = &object.Float{Value: toFloat(left)}
+		right = &object.Float{Value: toFloat(right)}
 		return evalInfixFloatExpression(left, operator, right)
+	case operator == "==":
+		return nativeBoolToBooleanObject(objectsEqual(left, right))
+	case operator == "!=":
+		return nativeBoolToBooleanObject(!objectsEqual(left, right))
+	case (operator == "<" || operator == ">") && left.Type() == right.Type() &&
+		(left.Type() == object.STRING_OBJ || left.Type() == object.ARRAY_OBJ || left.Type() == object.TUPLE_OBJ):
+		return evalOrderingExpression(left, operator, right)
 	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		return evalStringInfixExpression(left, operator, right)
-	case left.Type() == object.STRING_OBJ && right.Type() == object.INTEGER_OBJ:
-		val := int(right.(*object.Integer).Value)
-		strObj := &object.String{Value: strconv.Itoa(val)}
+	case left.Type() == object.STRING_OBJ && object.IsInteger(right.Type()):
+		strObj := &object.String{Value: right.Inspect()}
 		return evalStringInfixExpression(left, operator, strObj)
-	case left.Type() == object.INTEGER_OBJ && right.Type() == object.STRING_OBJ:
-		val := int(left.(*object.Integer).Value)
-		strObj := &object.String{Value: strconv.Itoa(val)}
+	case object.IsInteger(left.Type()) && right.Type() == object.STRING_OBJ:
+		strObj := &object.String{Value: left.Inspect()}
 		return evalStringInfixExpression(strObj, operator, right)
-	case operator == "==":
-		return nativeBoolToBooleanObject(left == right)
-	case operator == "!=":
-		return nativeBoolToBooleanObject(left != right)
 	case (left.Type() != right.Type() &&
 		(left.Type() != object.INTEGER_OBJ || left.Type() != object.FLOAT_OBJ) &&
 		(right.Type() != object.INTEGER_OBJ || right.Type() != object.FLOAT_OBJ)):
@@ -378,6 +354,18 @@ func evalInfixExpression(left object.Object, operator string,
 	}
 }
 
+// evalOrderingExpression orders strings, arrays and tuples with the same routine sort uses
+func evalOrderingExpression(left object.Object, operator string, right object.Object) object.Object {
+	cmp, err := compareObjects(left, right)
+	if err != nil {
+		return err
+	}
+	if operator == "<" {
+		return nativeBoolToBooleanObject(cmp < 0)
+	}
+	return nativeBoolToBooleanObject(cmp > 0)
+}
+
 func evalIfExpression(exp *ast.IfExpression, env *object.Enviroment) object.Object {
 	conditions := Eval(exp.Condition, env)
 
@@ -390,6 +378,9 @@ func evalIfExpression(exp *ast.IfExpression, env *object.Enviroment) object.Obje
 		condi_len := len(exp.Elif)
 		for i := 0; i < condi_len; i++ {
 			condis := Eval(exp.Elif[i].Conditions, env)
+			if isError(condis) {
+				return condis
+			}
 			if isTrue(condis) {
 				return Eval(exp.Elif[i].Consequences, env)
 			}
@@ -404,6 +395,9 @@ func evalIfExpression(exp *ast.IfExpression, env *object.Enviroment) object.Obje
 
 func evalTernaryExpression(exp *ast.TernaryExpression, env *object.Enviroment) object.Object {
 	condition := Eval(exp.Condition, env)
+	if isError(condition) {
+		return condition
+	}
 	if isTrue(condition) {
 		return Eval(exp.Consequence, env)
 	} else {
@@ -432,7 +426,7 @@ func evalProgram(obj *ast.Program, env *object.Enviroment) object.Object {
 
 		switch result := result.(type) {
 		case *object.ReturnValue:
-			return result.Value
+			return unwrapReturnValue(result)
 		case *object.Error:
 			return result
 		}
@@ -460,6 +454,15 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
+// withPosition stamps an error with the position of tok, unless a deeper node already did
+func withPosition(obj object.Object, tok token.Token) object.Object {
+	if err, ok := obj.(*object.Error); ok && err.Line == 0 {
+		err.Line = tok.Line
+		err.Column = tok.Column
+	}
+	return obj
+}
+
 func isError(obj object.Object) bool {
 	if obj != nil {
 		return obj.Type() == object.ERROR_OBJ
@@ -493,13 +496,63 @@ func evalExpressions(exps []ast.Expression, env *object.Enviroment) []object.Obj
 	return result
 }
 
+// Call applies a function or builtin object to args, for callers outside of an ast.CallExpression
+func Call(fn object.Object, args ...object.Object) object.Object {
+	return applyFunction(fn, args)
+}
+
+// evalFunctionDeclaration binds a `func name() {}` statement in env, the function
+// closes over env itself so it sees its own name and the ones declared after it
+func evalFunctionDeclaration(node *ast.FunctionExpression, env *object.Enviroment) {
+	env.Declare(node.Name.Value, newFunction(node, env))
+}
+
+func newFunction(node *ast.FunctionExpression, env *object.Enviroment) *object.Function {
+	return &object.Function{
+		Name:       node.Name,
+		Parameters: node.Parameters,
+		Defaults:   node.Defaults,
+		Rest:       node.Rest,
+		Env:        env,
+		Body:       node.Body,
+	}
+}
+
 func applyFunction(fn object.Object, args []object.Object) object.Object {
+	return applyFunctionNamed(fn, args, nil)
+}
+
+// applyFunctionNamed is applyFunction with the `name = value` arguments of a call.
+// The tail calls the function ends with are made here in a loop, so they do not
+// count towards the call depth
+func applyFunctionNamed(fn object.Object, args []object.Object, named map[string]object.Object) object.Object {
 
 	switch function := fn.(type) {
 	case *object.Function:
-		extendedEvn := extendFunctionEnv(function, args)
-		evaluated := Eval(function.Body, extendedEvn)
-		return unwrapReturnValue(evaluated)
+		if callDepth >= MaxCallDepth {
+			return newError("maximum call depth of %d exceeded", MaxCallDepth)
+		}
+		callDepth++
+		defer func() { callDepth-- }()
+
+		extendedEvn, err := extendFunctionEnv(function, args, named)
+		if err != nil {
+			return err
+		}
+		for {
+			evaluated := Eval(function.Body, extendedEvn)
+
+			returnValue, ok := evaluated.(*object.ReturnValue)
+			if !ok || returnValue.Tail == nil {
+				return unwrapReturnValue(evaluated)
+			}
+			tail := returnValue.Tail
+			function = tail.Function
+			extendedEvn, err = extendFunctionEnv(function, tail.Args, tail.Named)
+			if err != nil {
+				return withPosition(err, tail.Token)
+			}
+		}
 	case *object.Builtin:
 		return function.Fn(args...)
 	}
@@ -507,17 +560,67 @@ func applyFunction(fn object.Object, args []object.Object) object.Object {
 	return newError("not a function: %s", fn.Type())
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Enviroment {
+// extendFunctionEnv binds the parameters of fn. Positional arguments go first, then the
+// named ones, then the defaults of what is still missing. Defaults are evaluated at every
+// call in the new enviroment, so they can use the parameters before them
+func extendFunctionEnv(fn *object.Function, args []object.Object, named map[string]object.Object) (*object.Enviroment, *object.Error) {
 	env := object.NewEncloseEnviroment(fn.Env)
 
+	if len(args) > len(fn.Parameters) && fn.Rest == nil {
+		return nil, newError("too many arguments to %s: expect at most %d, got %d",
+			fn.Signature(), len(fn.Parameters), len(args))
+	}
+
+	for name := range named {
+		if !hasParameter(fn, name) {
+			return nil, newError("unknown argument `%s` to %s", name, fn.Signature())
+		}
+	}
+
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		value, isNamed := named[param.Value]
+		switch {
+		case paramIdx < len(args):
+			if isNamed {
+				return nil, newError("argument `%s` to %s is given more than once", param.Value, fn.Signature())
+			}
+			value = args[paramIdx]
+		case isNamed:
+		case paramIdx < len(fn.Defaults) && fn.Defaults[paramIdx] != nil:
+			value = Eval(fn.Defaults[paramIdx], env)
+			if err, ok := value.(*object.Error); ok {
+				return nil, err
+			}
+		default:
+			return nil, newError("missing argument `%s` to %s", param.Value, fn.Signature())
+		}
+		env.Declare(param.Value, value)
 	}
-	return env
+
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Declare(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+	return env, nil
+}
+
+func hasParameter(fn *object.Function, name string) bool {
+	for _, param := range fn.Parameters {
+		if param.Value == name {
+			return true
+		}
+	}
+	return false
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
 	if returnValue, ok := obj.(*object.ReturnValue); ok {
+		if tail := returnValue.Tail; tail != nil {
+			return withPosition(applyFunctionNamed(tail.Function, tail.Args, tail.Named), tail.Token)
+		}
 		return returnValue.Value
 	}
 
@@ -528,6 +631,16 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.TUPLE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalArrayIndexExpression(&object.Array{Elements: left.(*object.Tuple).Elements}, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
+	case index.Type() == object.BIG_INTEGER_OBJ && left.Type() == object.ARRAY_OBJ:
+		return bigIndexError(index, "an array", len(left.(*object.Array).Elements))
+	case index.Type() == object.BIG_INTEGER_OBJ && left.Type() == object.TUPLE_OBJ:
+		return bigIndexError(index, "a tuple", len(left.(*object.Tuple).Elements))
+	case index.Type() == object.BIG_INTEGER_OBJ && left.Type() == object.STRING_OBJ:
+		return bigIndexError(index, "a string", utf8.RuneCountInString(left.(*object.String).Value))
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
 	default:
@@ -535,6 +648,25 @@ func evalIndexExpression(left, index object.Object) object.Object {
 	}
 }
 
+// bigIndexError is the error for an index too big for an INTEGER, it can never
+// be inside an array, a tuple or a string
+func bigIndexError(index object.Object, of string, length int) *object.Error {
+	return newError("index %s out of range for %s of length %d", index.Inspect(), of, length)
+}
+
+// evalStringIndexExpression returns the character at the index as a string,
+// indexes count characters and not bytes
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
+	idx := index.(*object.Integer).Value
+
+	if idx < 0 || idx >= int64(len(runes)) {
+		return NULL
+	}
+
+	return &object.String{Value: string(runes[idx])}
+}
+
 func evalArrayIndexExpression(arr, index object.Object) object.Object {
 	arrObj := arr.(*object.Array)
 	idex := index.(*object.Integer).Value
@@ -547,37 +679,59 @@ func evalArrayIndexExpression(arr, index object.Object) object.Object {
 	return arrObj.Elements[idex]
 }
 
+func evalArrayLiteral(node *ast.ArrayLiteral, env *object.Enviroment) object.Object {
+	arr := &object.Array{}
+	if node.ElementType != nil {
+		elementType, ok := object.ElementTypes[node.ElementType.Value]
+		if !ok {
+			return withPosition(newError("unknown array element type: %s", node.ElementType.Value),
+				node.ElementType.Token)
+		}
+		arr.ElementType = elementType
+	}
+
+	elements := evalExpressions(node.Elements, env)
+	if len(elements) == 1 && isError(elements[0]) {
+		return elements[0]
+	}
+	for _, element := range elements {
+		if !arr.Accepts(element) {
+			return withPosition(newError("Type mismatch, cannot put %s into an array of %s",
+				typeOf(element), arr.ElementType), node.Token)
+		}
+	}
+	arr.Elements = elements
+	return arr
+}
+
 func evalHashLiteral(node *ast.HashLiteral, env *object.Enviroment) object.Object {
-	pairs := make(map[object.HashKey]object.HashPair)
+	hash := object.NewHash()
 
-	keys := []object.Object{}
-	for keyNode, valNode := range node.Pairs {
+	for _, keyNode := range node.Keys {
 		key := Eval(keyNode, env)
 		if isError(key) {
 			return key
 		}
-		keys = append(keys, key)
-		hashKey, ok := key.(object.Hashable)
+		hashKey, ok := object.AsHashable(key)
 		if !ok {
 			return newError("unusable hash key %s", key.Type())
 		}
 
-		val := Eval(valNode, env)
+		val := Eval(node.Pairs[keyNode], env)
 		if isError(val) {
 			return val
 		}
 
-		hashed := hashKey.HashKey()
-		pairs[hashed] = object.HashPair{Key: key, Value: val}
+		hash.Set(hashKey, val)
 	}
 
-	return &object.Hash{Pairs: pairs, Keys: keys}
+	return hash
 }
 
 func evalHashIndexExpression(hash, index object.Object) object.Object {
 	hashObj := hash.(*object.Hash)
 
-	key, ok := index.(object.Hashable)
+	key, ok := object.AsHashable(index)
 	if !ok {
 		return newError("unusable as hash key: %s", index.Type())
 	}
@@ -590,22 +744,42 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 	return pair.Value
 }
 
+// evalForExpression gives every iteration its own copy of the loop enviroment, the
+// step runs in the copy for the next one. A closure made in the body keeps the
+// values of its own iteration instead of seeing them change afterwards
 func evalForExpression(forNode *object.For, env *object.Enviroment) object.Object {
 	envInner := object.NewEncloseEnviroment(env)
 	forNode.Env = envInner
 
 	if forNode.Identifer != nil {
-		Eval(forNode.Identifer, envInner)
+		if ident := Eval(forNode.Identifer, envInner); isError(ident) {
+			return ident
+		}
 	}
 
-	for Eval(forNode.Condition[0], envInner) == TRUE {
+	for {
+		condition := Eval(forNode.Condition[0], envInner)
+		if isError(condition) {
+			return condition
+		}
+		if condition != TRUE {
+			break
+		}
 		result := Eval(forNode.Body, envInner)
 
-		if result != nil && result.Type() == object.RETURN_VALUE_OBJ {
-			return result
+		if result != nil {
+			rt := result.Type()
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
 		}
+
+		envInner = envInner.Copy()
+		forNode.Env = envInner
 		if len(forNode.Condition) == 2 {
-			Eval(forNode.Condition[1], envInner)
+			if step := Eval(forNode.Condition[1], envInner); isError(step) {
+				return step
+			}
 		}
 	}
 
@@ -125,6 +125,9 @@ func TestTernaryExpression(t *testing.T) {
 		{"(3 == 3) ? 5 : 1", 5},
 		{"(false) ? 7 : 3", 3},
 		{"(!false) ? 7 : 3", 7},
+		{"1 == 2 ? 1 : 2 == 2 ? 2 : 3", 2},
+		{"true ? false ? 1 : 2 : 3", 2},
+		{"3 > 2 ? 4 + 1 : 0", 5},
 	}
 
 	for _, test := range tests {
@@ -154,6 +157,10 @@ func TestReturnStatments(t *testing.T) {
 		eval := testEval(test.input)
 		testIntegerObject(t, eval, test.expected)
 	}
+
+	bare := "func f(x) {\n    perhaps (x) {\n        sayless\n    }\n    x + 1\n}\n"
+	testInspected(t, bare+"f(nocap)", testEval(bare+"f(nocap)"), "null")
+	testInspected(t, "sayless;", testEval("sayless;"), "null")
 }
 
 func TestErrorHandling(t *testing.T) {
@@ -197,6 +204,18 @@ func TestErrorHandling(t *testing.T) {
 			`{"name": "Monkey"}[func(x){x}];`,
 			"unusable as hash key: FUNCTION",
 		},
+		{
+			"[1, 2][9223372036854775808]",
+			"index 9223372036854775808 out of range for an array of length 2",
+		},
+		{
+			"(1,)[-2 ** 70]",
+			"index -1180591620717411303424 out of range for a tuple of length 1",
+		},
+		{
+			`"héllo"[2 ** 64]`,
+			"index 18446744073709551616 out of range for a string of length 5",
+		},
 	}
 
 	for _, test := range tests {
@@ -309,6 +328,12 @@ func TestBuiltinFunction(t *testing.T) {
 		{`len("hello")`, 5},
 		{`len(1)`, "argument to `len` not supported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments, expect=1, got=2"},
+		{`pop([1, 2, 3])`, 3},
+		{`pop([1, 2, 3], 0)`, 1},
+		{`pop([])`, "cannot pop from an empty array"},
+		{`pop()`, "Unexpect amount of arguement, expect=2 (Array, index), or 1 (Array)"},
+		{`pop([1, 2], -1)`, "Error: index out of range, array contain=2 elements"},
+		{`pop([1, 2], 2)`, "Error: index out of range, array contain=2 elements"},
 	}
 	for _, test := range tests {
 		eval := testEval(test.input)
@@ -346,6 +371,49 @@ func TestArrayLiteral(t *testing.T) {
 	testIntegerObject(t, result.Elements[2], 6)
 }
 
+func TestMixedArrayLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[]`, "[]"},
+		{`[1, 2.5, "three", nocap]`, "[1, 2.5, three, true]"},
+		{`[[1, "a"], [], {"b": 2}]`, "[[1, a], [], {b: 2}]"},
+		{`[int: 1, 2, 3]`, "[1, 2, 3]"},
+		{`[float:]`, "[]"},
+		{`[array: [1], ["a"]]`, "[[1], [a]]"},
+		{`propose fs = [func: len, func(x) { x }]; propose f = fs[0]; f("ab")`, "2"},
+		{`propose a = []; a = [1, "b"]; a`, "[1, b]"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTypedArrayErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[int: 1, 2.5]`, "Type mismatch, cannot put FLOAT into an array of INTEGER"},
+		{`[string: "a", ["b"]]`, "Type mismatch, cannot put ARRAY into an array of STRING"},
+		{`[number: 1]`, "unknown array element type: number"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
 func TestForLoopOpperation(t *testing.T) {
 	input := `
         propose a = 4;
@@ -415,6 +483,7 @@ func testEval(input string) object.Object {
 	p := parser.New(l)
 	program := p.ParserProgram()
 	env := object.NewEnviroment()
+	Reset()
 
 	return Eval(program, env)
 }
@@ -608,3 +677,77 @@ func testStringObject(t *testing.T, obj object.Object, expected string) bool {
 	}
 	return true
 }
+
+func TestAssertionBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`assert(1 < 2)`, ""},
+		{`assert(1 > 2)`, "assert failed: condition is not true"},
+		{`assert(cap, "oops")`, "assert failed: oops (condition is not true)"},
+		{`assertEqual(2 + 2, 4)`, ""},
+		{`assertEqual(4, 4.0)`, ""},
+		{`assertEqual("4", 4)`, "assertEqual failed: expect=4, got=\"4\""},
+		{`assertEqual([[1], [2, 3]], [[1], [2, 3]])`, ""},
+		{`assertEqual([[1], [2, 3]], [[1], [2, 4]])`, "assertEqual failed: expect=[[1], [2, 4]], got=[[1], [2, 3]]"},
+		{`assertEqual({"a": [1], "b": 2}, {"b": 2, "a": [1]})`, ""},
+		{`assertEqual({"a": 1}, {"a": 2})`, "assertEqual failed: expect={a: 2}, got={a: 1}"},
+		{`assertNotEqual([1], [2])`, ""},
+		{`assertNotEqual("a", "a")`, "assertNotEqual failed: both values are \"a\""},
+		{`assertApprox(0.1 + 0.2, 0.3)`, ""},
+		{`assertApprox(1.5, 1, 0.5)`, ""},
+		{`assertApprox(1.6, 1, 0.5)`, "assertApprox failed: expect=1 (±0.5), got=1.6"},
+		{`assertApprox("1", 1)`, "Argument type error: expect Int or Float, got STRING"},
+		{`assertError(func() { 1 / 0 })`, ""},
+		{`assertError(func() { 1 / 0 }, "zero division")`, ""},
+		{`assertError(func() { 1 / 0 }, "type mismatch")`, "assertError failed: expect error containing \"type mismatch\", got \"zero division error: 1 / 0\""},
+		{`assertError(func() { 1 })`, "assertError failed: expect an error, got 1"},
+		{`assertError(1)`, "Argument type error: expect FUNCTION, got INTEGER"},
+		{`assertEqual(1)`, "wrong number of arguments, expect=2 or 3, got=1"},
+	}
+
+	for _, test := range tests {
+		eval := testEval(test.input)
+
+		if test.expected == "" {
+			testNullObject(t, eval)
+			continue
+		}
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", test.input, eval, eval)
+			continue
+		}
+		if errObj.Message != test.expected {
+			t.Errorf("wrong error message. expect=%s, got=%s", test.expected, errObj.Message)
+		}
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	tests := []struct {
+		input  string
+		line   int
+		column int
+	}{
+		{"5 + true;", 1, 3},
+		{"propose a = 1;\n  assert(a == 2);", 2, 3},
+		{"for (propose i = 0; i < 3; ++i) {\n    assertEqual(i, 0);\n}", 2, 5},
+		{"func f() {\n  missing\n}\nf()", 2, 3},
+		{"func f(a) { a }\n  f(1, 2)", 2, 3},
+	}
+
+	for _, test := range tests {
+		eval := testEval(test.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%q: object is not Error, got=%T (%+v)", test.input, eval, eval)
+			continue
+		}
+		if errObj.Line != test.line || errObj.Column != test.column {
+			t.Errorf("%q: position error: expect=%d:%d, got=%d:%d", test.input,
+				test.line, test.column, errObj.Line, errObj.Column)
+		}
+	}
+}
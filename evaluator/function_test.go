@@ -0,0 +1,67 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestFunctionArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose f = func(a, b = 10) { a + b }; f(1)", "11"},
+		{"propose f = func(a, b = 10) { a + b }; f(1, 2)", "3"},
+		{"propose f = func(a, b = a * 2) { b }; f(4)", "8"},
+		{"propose f = func(a = []) { append(a, 1) }; f(); f()", "[1]"},
+		{"propose f = func(...xs) { xs }; f()", "[]"},
+		{"propose f = func(...xs) { xs }; f(1, 2, 3)", "[1, 2, 3]"},
+		{"propose f = func(first, ...others) { [first, len(others)] }; f(1, 2, 3)", "[1, 2]"},
+		{"propose f = func(a, b = 2, ...rest) { [a, b, rest] }; f(1)", "[1, 2, []]"},
+		{"propose f = func(a, b = 2, ...rest) { [a, b, rest] }; f(1, 3, 5, 7)", "[1, 3, [5, 7]]"},
+		{`propose greet = func(name, greeting = "hi") { greeting + " " + name }; greet("ann", greeting = "yo")`, "yo ann"},
+		{`propose greet = func(name, greeting = "hi") { greeting + " " + name }; greet(greeting = "hey", name = "bob")`, "hey bob"},
+		{"propose f = func(a, b = 1, c = 2) { [a, b, c] }; f(0, c = 5)", "[0, 1, 5]"},
+		{"propose n = 5; propose f = func(n) { n }; f(1); n", "5"},
+		{"func add(a, b = 1) { a + b }; add(2)", "3"},
+		{"map([1, 2], func(x, i, scale = 10) { x * scale })", "[10, 20]"},
+		{"map([1, 2], func(...args) { len(args) })", "[2, 2]"},
+		{"func(a, b = 1, ...rest) { a }", "func(a, b = 1, ...rest) {\na\n}"},
+		{"func add(a, b) { a + b }; add", "func add(a, b) {\n(a + b)\n}"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFunctionArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose f = func(a, b) { a }; f(1)", "missing argument `b` to func(a, b)"},
+		{"propose f = func(a, b) { a }; f()", "missing argument `a` to func(a, b)"},
+		{"propose f = func(a) { a }; f(1, 2)", "too many arguments to func(a): expect at most 1, got 2"},
+		{"func add(a, b = 1) { a + b }; add(1, 2, 3)", "too many arguments to func add(a, b = 1): expect at most 2, got 3"},
+		{"propose f = func(a) { a }; f(b = 1)", "unknown argument `b` to func(a)"},
+		{"propose f = func(a) { a }; f(1, a = 2)", "argument `a` to func(a) is given more than once"},
+		{"propose f = func(a) { a }; f(a = 1, a = 2)", "argument `a` is given more than once"},
+		{"propose f = func(...xs) { xs }; f(xs = 1)", "unknown argument `xs` to func(...xs)"},
+		{"propose f = func(a = missing) { a }; f()", "identifier not found: missing"},
+		{"len(x = 1)", "builtin functions do not take named arguments"},
+		{"map([1], func(x, i, j) { x })", "function passed to `map` takes 3 arguments, expect at most 2"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestNumberHashKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{1.2: "a", 1.7: "b"}`, "{1.2: a, 1.7: b}"},
+		{`{1.2: "a", 1.7: "b"}[1.2]`, "a"},
+		{`{1: "a"}[1.0]`, "a"},
+		{`{1: "a"}[1.00d]`, "a"},
+		{`{1.0: "a"}[1]`, "a"},
+		{`{1: "a", 1.0: "b"}`, "{1: b}"},
+		{`{0: "zero"}[-0.0]`, "zero"},
+		{`{0.5: "a"}[0.5d]`, "null"},
+		{`{0.5d: "a"}[0.50d]`, "a"},
+		{`{2 ** 64: "big"}[2.0 ** 64]`, "big"},
+		{`propose nan = float("nan"); {nan: "a"}[nan]`, "a"},
+		{`propose inf = float("inf"); has({inf: 1}, -inf)`, "false"},
+		{`has({1: 1}, true)`, "false"},
+		{`has({1: 1}, "1")`, "false"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFrozenArrayKeys(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`propose p = freeze([1, 2]); {p: "a"}[freeze([1, 2])]`, "a"},
+		{`propose grid = {freeze([0, 0]): "origin", freeze([0, 1]): "up"}; grid[freeze([0, 1])]`, "up"},
+		{`{freeze([1, 2]): "a"}[freeze([2, 1])]`, "null"},
+		{`{freeze([1, [2, 3]]): "a"}[freeze([1.0, [2, 3]])]`, "a"},
+		{`freeze([1, [2]])`, "[1, [2]]"},
+		{`freeze([int: 1, 2]) == [1, 2]`, "true"},
+		{`isFrozen(freeze([1]))`, "true"},
+		{`isFrozen([1])`, "false"},
+		{`propose a = [1, 2]; propose f = freeze(a); pop(a); f`, "[1, 2]"},
+		{`isFrozen(append(freeze([1]), 2))`, "false"},
+		{`keys({freeze([1]): 1})`, "[[1]]"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestHashKeyErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{[1, 2]: "a"}`, "unusable hash key ARRAY"},
+		{`{"a": 1}[[1]]`, "unusable as hash key: ARRAY"},
+		{`has({"a": 1}, [1])`, "unusable as hash key: ARRAY"},
+		{`freeze([1, {"a": 1}])`, "cannot freeze an array holding a HASH, it is not hashable"},
+		{`freeze([[{"a": 1}]])`, "cannot freeze an array holding a HASH, it is not hashable"},
+		{`freeze("abc")`, "argument 1 to `freeze` must be ARRAY, got STRING"},
+		{`pop(freeze([1, 2]))`, "cannot pop from a frozen array"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
+
+func TestFreezeCycle(t *testing.T) {
+	arr := &object.Array{Elements: []object.Object{&object.Integer{Value: 1}}}
+	arr.Elements = append(arr.Elements, arr)
+
+	if _, err := freeze(arr, map[*object.Array]bool{}); err == nil {
+		t.Errorf("freezing a cyclic array did not fail")
+	}
+}
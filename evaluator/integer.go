@@ -0,0 +1,244 @@
+package evaluator
+
+import (
+	"math"
+	"math/big"
+	"yap/object"
+)
+
+// maxBigIntegerBits stops `**` from trying to build numbers that would eat all the memory
+const maxBigIntegerBits = 1 << 24
+
+// maxShiftCount is the most bits a number can be shifted by, for the same reason
+const maxShiftCount = maxBigIntegerBits
+
+// evalIntegerInfixExpression handles Integer and BigInteger operands. Integer
+// arithmetic is checked for overflow and redone with big integers when it happens
+func evalIntegerInfixExpression(left object.Object, operator string, right object.Object) object.Object {
+	if operator == "<<" || operator == ">>" {
+		count := toBigInt(right)
+		if count.Sign() < 0 {
+			return newError("negative shift count: %s %s %s", left.Inspect(), operator, count)
+		}
+		if !count.IsInt64() || count.Int64() > maxShiftCount {
+			return newError("shift count too large: %s %s %s, the most is %d",
+				left.Inspect(), operator, count, maxShiftCount)
+		}
+	}
+	l, lok := left.(*object.Integer)
+	r, rok := right.(*object.Integer)
+	if lok && rok {
+		if result, ok := evalInfixIntExpression(l.Value, operator, r.Value); ok {
+			return result
+		}
+	}
+	return evalBigIntegerInfixExpression(toBigInt(left), operator, toBigInt(right))
+}
+
+// evalInfixIntExpression returns false when the result does not fit in an int64
+func evalInfixIntExpression(l_val int64, operator string, r_val int64) (object.Object, bool) {
+	switch operator {
+	case "+":
+		sum := l_val + r_val
+		return &object.Integer{Value: sum}, (sum > l_val) == (r_val > 0)
+	case "-":
+		diff := l_val - r_val
+		return &object.Integer{Value: diff}, (diff < l_val) == (r_val > 0)
+	case "*":
+		if l_val == 0 || r_val == 0 {
+			return &object.Integer{Value: 0}, true
+		}
+		product := l_val * r_val
+		overflow := product/r_val != l_val ||
+			(l_val == -1 && r_val == math.MinInt64) || (r_val == -1 && l_val == math.MinInt64)
+		return &object.Integer{Value: product}, !overflow
+	case "%":
+		if r_val == 0 {
+			return newError("zero division error: %d %% 0", l_val), true
+		}
+		return &object.Integer{Value: (l_val % r_val)}, true
+	case "/":
+		if r_val == 0 {
+			return newError("zero division error: %d / 0", l_val), true
+		}
+		if l_val == math.MinInt64 && r_val == -1 {
+			return nil, false
+		}
+		return &object.Integer{Value: l_val / r_val}, true
+	case "**":
+		return nil, false
+	case "&":
+		return &object.Integer{Value: l_val & r_val}, true
+	case "|":
+		return &object.Integer{Value: l_val | r_val}, true
+	case "^":
+		return &object.Integer{Value: l_val ^ r_val}, true
+	case "<<":
+		// the count was checked to not be negative
+		if l_val == 0 {
+			return &object.Integer{Value: 0}, true
+		}
+		if r_val >= 63 {
+			return nil, false
+		}
+		shifted := l_val << r_val
+		return &object.Integer{Value: shifted}, shifted>>r_val == l_val
+	case ">>":
+		return &object.Integer{Value: l_val >> r_val}, true
+	case "<":
+		return nativeBoolToBooleanObject(l_val < r_val), true
+	case ">":
+		return nativeBoolToBooleanObject(l_val > r_val), true
+	case "==":
+		return nativeBoolToBooleanObject(l_val == r_val), true
+	case "!=":
+		return nativeBoolToBooleanObject(l_val != r_val), true
+	default:
+		return newError("unknown operator: %s %s %s",
+			object.INTEGER_OBJ, operator, object.INTEGER_OBJ), true
+	}
+}
+
+func evalBigIntegerInfixExpression(l_val *big.Int, operator string, r_val *big.Int) object.Object {
+	switch operator {
+	case "+":
+		return normalizeBigInt(new(big.Int).Add(l_val, r_val))
+	case "-":
+		return normalizeBigInt(new(big.Int).Sub(l_val, r_val))
+	case "*":
+		return normalizeBigInt(new(big.Int).Mul(l_val, r_val))
+	case "%":
+		if r_val.Sign() == 0 {
+			return newError("zero division error: %s %% 0", l_val)
+		}
+		return normalizeBigInt(new(big.Int).Rem(l_val, r_val))
+	case "/":
+		if r_val.Sign() == 0 {
+			return newError("zero division error: %s / 0", l_val)
+		}
+		return normalizeBigInt(new(big.Int).Quo(l_val, r_val))
+	case "**":
+		return evalIntegerPower(l_val, r_val)
+	case "&":
+		return normalizeBigInt(new(big.Int).And(l_val, r_val))
+	case "|":
+		return normalizeBigInt(new(big.Int).Or(l_val, r_val))
+	case "^":
+		return normalizeBigInt(new(big.Int).Xor(l_val, r_val))
+	case "<<":
+		return normalizeBigInt(new(big.Int).Lsh(l_val, uint(r_val.Int64())))
+	case ">>":
+		return normalizeBigInt(new(big.Int).Rsh(l_val, uint(r_val.Int64())))
+	case "<":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) < 0)
+	case ">":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) > 0)
+	case "==":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) == 0)
+	case "!=":
+		return nativeBoolToBooleanObject(l_val.Cmp(r_val) != 0)
+	default:
+		return newError("unknown operator: %s %s %s",
+			integerType(l_val), operator, integerType(r_val))
+	}
+}
+
+// evalIntegerPower is exact for every exponent that is not negative,
+// a negative one gives a Float like 2 ** -1 = 0.5
+func evalIntegerPower(base, exp *big.Int) object.Object {
+	if exp.Sign() < 0 {
+		return &object.Float{Value: math.Pow(bigIntToFloat(base), bigIntToFloat(exp))}
+	}
+
+	// 0, 1 and -1 stay small no matter how big the exponent is
+	if base.CmpAbs(big.NewInt(1)) <= 0 {
+		if base.Sign() < 0 && exp.Bit(0) == 0 {
+			return &object.Integer{Value: 1}
+		}
+		if exp.Sign() == 0 {
+			return &object.Integer{Value: 1}
+		}
+		return normalizeBigInt(new(big.Int).Set(base))
+	}
+	// the result has at least (bits - 1) * exp bits, divided so it cannot overflow
+	bits := int64(base.BitLen() - 1)
+	if !exp.IsInt64() || bits > 0 && exp.Int64() > maxBigIntegerBits/bits {
+		return newError("integer overflow: %s ** %s is too large", base, exp)
+	}
+	return normalizeBigInt(new(big.Int).Exp(base, exp, nil))
+}
+
+func evalNegativeIntegerExpression(obj object.Object) object.Object {
+	if i, ok := obj.(*object.Integer); ok && i.Value != math.MinInt64 {
+		return &object.Integer{Value: -i.Value}
+	}
+	return normalizeBigInt(new(big.Int).Neg(toBigInt(obj)))
+}
+
+// evalBitwiseNotExpression flips every bit of an int, so ~x is -x - 1
+func evalBitwiseNotExpression(obj object.Object) object.Object {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: ^obj.Value}
+	case *object.BigInteger:
+		return normalizeBigInt(new(big.Int).Not(obj.Value))
+	default:
+		return newError("unknown operator: ~%s", obj.Type())
+	}
+}
+
+// normalizeBigInt gives back an Integer whenever value fits in one
+func normalizeBigInt(value *big.Int) object.Object {
+	if value.IsInt64() {
+		return &object.Integer{Value: value.Int64()}
+	}
+	return &object.BigInteger{Value: value}
+}
+
+func toBigInt(obj object.Object) *big.Int {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return big.NewInt(obj.Value)
+	case *object.BigInteger:
+		return obj.Value
+	default:
+		return nil
+	}
+}
+
+func bigIntToFloat(value *big.Int) float64 {
+	f, _ := new(big.Float).SetInt(value).Float64()
+	return f
+}
+
+// toFloat converts any number to a float64
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.BigInteger:
+		return bigIntToFloat(obj.Value)
+	case *object.Float:
+		return obj.Value
+	case *object.Decimal:
+		return obj.Float64()
+	default:
+		return math.NaN()
+	}
+}
+
+func integerType(value *big.Int) object.ObjectType {
+	if value.IsInt64() {
+		return object.INTEGER_OBJ
+	}
+	return object.BIG_INTEGER_OBJ
+}
+
+func isNumber(obj object.Object) bool {
+	switch obj.Type() {
+	case object.INTEGER_OBJ, object.BIG_INTEGER_OBJ, object.FLOAT_OBJ, object.DECIMAL_OBJ:
+		return true
+	default:
+		return false
+	}
+}
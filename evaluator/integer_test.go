@@ -0,0 +1,141 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestIntegerOverflow(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"9223372036854775807 + 1", "9223372036854775808"},
+		{"-9223372036854775807 - 2", "-9223372036854775809"},
+		{"9223372036854775807 * 2", "18446744073709551614"},
+		{"-9223372036854775807 - 1", "-9223372036854775808"},
+		{"(-9223372036854775807 - 1) / -1", "9223372036854775808"},
+		{"-(-9223372036854775807 - 1)", "9223372036854775808"},
+		{"3037000500 * 3037000500", "9223372037000250000"},
+		{"2 ** 63", "9223372036854775808"},
+		{"2 ** 100", "1267650600228229401496703205376"},
+		{"(-3) ** 41", "-36472996377170786403"},
+		{"2 ** 62", "4611686018427387904"},
+		{"2 ** -1", "0.5"},
+		{"0 ** 0", "1"},
+		{"2 ** 3 ** 2", "512"},
+		{"-2 ** 2", "-4"},
+		{"2 * 3 ** 2", "18"},
+		{"(-1) ** 1000000000001", "-1"},
+		{"123456789012345678901234567890", "123456789012345678901234567890"},
+		{"123456789012345678901234567890 + 1", "123456789012345678901234567891"},
+		{"123456789012345678901234567890 % 11", "7"},
+		{"123456789012345678901234567890 / 10", "12345678901234567890123456789"},
+		{"(2 ** 64) - (2 ** 64) + 5", "5"},
+		{"(2 ** 64) / (2 ** 60)", "16"},
+		{"2 ** 64 > 9223372036854775807", "true"},
+		{"2 ** 64 < 2 ** 65", "true"},
+		{"2 ** 64 == 18446744073709551616", "true"},
+		{"2 ** 64 == 2 ** 64 + 1", "false"},
+		{"2 ** 64 + 0.5", "1.8446744073709552e+19"},
+		{`"n=" + 2 ** 64`, "n=18446744073709551616"},
+		{"propose f = func(n) { perhaps (n < 2) { 1 } otherwise { n * f(n - 1) } }; f(25)",
+			"15511210043330985984000000"},
+		{"propose a = 1; a = 2 ** 64; a", "18446744073709551616"},
+		{"propose a = 9223372036854775807; ++a; a", "9223372036854775808"},
+		{"{2 ** 64: 1, 2 ** 65: 2}[2 ** 64]", "1"},
+		{"has({2 ** 64: 1}, 2 ** 64 + 1)", "false"},
+		{"[int: 1, 2 ** 64]", "[1, 18446744073709551616]"},
+		{"sort([2 ** 64, 3, -(2 ** 70), 2.5])", "[-1180591620717411303424, 2.5, 3, 18446744073709551616]"},
+		{`int("123456789012345678901234567890")`, "123456789012345678901234567890"},
+		{`int(10.0 ** 20)`, "100000000000000000000"},
+		{`int(-2.9)`, "-2"},
+		{`float(2 ** 64)`, "1.8446744073709552e+19"},
+		{`float("2.5")`, "2.5"},
+		{`float(3)`, "3"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIntegerNormalization(t *testing.T) {
+	eval := testEval("(2 ** 64) / (2 ** 32)")
+	testIntegerObject(t, eval, 4294967296)
+
+	eval = testEval("2 ** 64")
+	if _, ok := eval.(*object.BigInteger); !ok {
+		t.Errorf("object is not BigInteger, got=%T (%+v)", eval, eval)
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"6 & 3", "2"},
+		{"6 | 3", "7"},
+		{"6 ^ 3", "5"},
+		{"~5", "-6"},
+		{"~-1", "0"},
+		{"1 << 4", "16"},
+		{"-16 >> 2", "-4"},
+		{"5 >> 10", "0"},
+		{"-5 >> 100", "-1"},
+		{"0 << 1000", "0"},
+		{"1 << 63", "9223372036854775808"},
+		{"-1 << 63", "-9223372036854775808"},
+		{"3 << 62", "13835058055282163712"},
+		{"(1 << 100) >> 98", "4"},
+		{"(1 << 64) | 1", "18446744073709551617"},
+		{"((1 << 64) | 0xFF) & 0xF0", "240"},
+		{"(1 << 64) ^ (1 << 64)", "0"},
+		{"~(1 << 64)", "-18446744073709551617"},
+		{"1 + 2 << 3", "24"},
+		{"0b1100 & 0b1010 | 0b0001", "9"},
+		{"5 & 3 == 1", "true"},
+		{"propose flags = 0; flags = flags | 1 << 3; flags & 8 != 0", "true"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIntegerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"2 ** 64 / 0", "zero division error: 18446744073709551616 / 0"},
+		{"2 ** 64 % 0", "zero division error: 18446744073709551616 % 0"},
+		{"2 ** 100000000", "integer overflow: 2 ** 100000000 is too large"},
+		{"1024 ** 1152921504606846976", "integer overflow: 1024 ** 1152921504606846976 is too large"},
+		{"1 << -1", "negative shift count: 1 << -1"},
+		{"(2 ** 64) >> -3", "negative shift count: 18446744073709551616 >> -3"},
+		{"1 << 16777217", "shift count too large: 1 << 16777217, the most is 16777216"},
+		{"1 >> (2 ** 64)", "shift count too large: 1 >> 18446744073709551616, the most is 16777216"},
+		{"1.5 & 1", "unknown operator: FLOAT & INTEGER, bitwise operators only work on ints"},
+		{`"a" | "b"`, "unknown operator: STRING | STRING, bitwise operators only work on ints"},
+		{"1d << 1", "unknown operator: DECIMAL << INTEGER, bitwise operators only work on ints"},
+		{"~1.5", "unknown operator: ~FLOAT"},
+		{`int("12a")`, "Cannot convert 12a into an Int"},
+		{`float("x")`, "Cannot convert x into a Float"},
+		{`propose a = 2 ** 64; a = "x"`,
+			"type mismatch error: could not set STRING into 'a' variable (Type = BIG_INTEGER)"},
+	}
+
+	for _, tt := range tests {
+		eval := testEval(tt.input)
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", tt.input, eval, eval)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package evaluator
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"yap/ast"
+	"yap/object"
+)
+
+// maxFormatWidth bounds the width and precision of a format specifier, so a
+// typo like {x:99999999} does not build a huge string
+const maxFormatWidth = 1000
+
+// formatSpec is a parsed format specifier `[[fill]align][0][width][.precision]`
+// like `>8`, `*^10`, `08.3` or `.2`
+type formatSpec struct {
+	fill  string
+	align byte
+	zero  bool
+	width int
+	// precision is -1 when it is not given
+	precision int
+}
+
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Enviroment) object.Object {
+	var str strings.Builder
+
+	str.WriteString(node.Texts[0])
+	for i, value := range node.Values {
+		val := Eval(value, env)
+		if isError(val) {
+			return val
+		}
+		text, err := formatValue(val, node.Formats[i])
+		if err != nil {
+			return withPosition(err, node.Token)
+		}
+		str.WriteString(text)
+		str.WriteString(node.Texts[i+1])
+	}
+
+	return &object.String{Value: str.String()}
+}
+
+// formatValue turns val into its Inspect text laid out by the format specifier
+func formatValue(val object.Object, format string) (string, *object.Error) {
+	if val == nil {
+		val = NULL
+	}
+	spec, ok := parseFormatSpec(format)
+	if !ok {
+		return "", newError("invalid format specifier %q", format)
+	}
+
+	text := val.Inspect()
+	if spec.precision >= 0 {
+		switch val := val.(type) {
+		case *object.Float:
+			text = strconv.FormatFloat(val.Value, 'f', spec.precision, 64)
+		case *object.Integer:
+			text = fixedDecimal(object.NewDecimalFromInt(big.NewInt(val.Value)), spec.precision)
+		case *object.BigInteger:
+			text = fixedDecimal(object.NewDecimalFromInt(val.Value), spec.precision)
+		case *object.Decimal:
+			text = fixedDecimal(val, spec.precision)
+		case *object.String:
+			if utf8.RuneCountInString(text) > spec.precision {
+				text = string([]rune(text)[:spec.precision])
+			}
+		default:
+			return "", newError("format specifier %q has a precision, which %s does not take",
+				format, val.Type())
+		}
+	}
+
+	padding := spec.width - utf8.RuneCountInString(text)
+	if padding <= 0 {
+		return text, nil
+	}
+	numeric := isNumber(val)
+	if spec.zero && spec.align == 0 && numeric {
+		// zeros go between the sign and the digits, like -0042
+		if strings.HasPrefix(text, "-") {
+			return "-" + strings.Repeat("0", padding) + text[1:], nil
+		}
+		return strings.Repeat("0", padding) + text, nil
+	}
+
+	fill := spec.fill
+	if spec.zero && spec.fill == " " {
+		fill = "0"
+	}
+	align := spec.align
+	if align == 0 {
+		align = '<'
+		if numeric {
+			align = '>'
+		}
+	}
+	switch align {
+	case '<':
+		return text + strings.Repeat(fill, padding), nil
+	case '>':
+		return strings.Repeat(fill, padding) + text, nil
+	default:
+		left := padding / 2
+		return strings.Repeat(fill, left) + text + strings.Repeat(fill, padding-left), nil
+	}
+}
+
+func parseFormatSpec(format string) (formatSpec, bool) {
+	spec := formatSpec{fill: " ", precision: -1}
+	runes := []rune(format)
+	i := 0
+
+	isAlign := func(r rune) bool { return r == '<' || r == '>' || r == '^' }
+	if len(runes) >= 2 && isAlign(runes[1]) {
+		spec.fill, spec.align = string(runes[0]), byte(runes[1])
+		i = 2
+	} else if len(runes) >= 1 && isAlign(runes[0]) {
+		spec.align = byte(runes[0])
+		i = 1
+	}
+
+	if i < len(runes) && runes[i] == '0' {
+		spec.zero = true
+		i++
+	}
+
+	readNumber := func() (int, bool) {
+		start := i
+		for i < len(runes) && '0' <= runes[i] && runes[i] <= '9' {
+			i++
+		}
+		if i == start {
+			return 0, false
+		}
+		n, err := strconv.Atoi(string(runes[start:i]))
+		return n, err == nil && n <= maxFormatWidth
+	}
+
+	if i < len(runes) && runes[i] != '.' {
+		width, ok := readNumber()
+		if !ok {
+			return spec, false
+		}
+		spec.width = width
+	}
+	if i < len(runes) && runes[i] == '.' {
+		i++
+		precision, ok := readNumber()
+		if !ok {
+			return spec, false
+		}
+		spec.precision = precision
+	}
+
+	return spec, i == len(runes)
+}
+
+// fixedDecimal writes d with exactly places digits after the point, rounding it
+// with the rounding mode of decimalRounding
+func fixedDecimal(d *object.Decimal, places int) string {
+	rounded := d.Round(int32(places), decimalContext.rounding)
+	text := rounded.Inspect()
+	if missing := places - int(rounded.Scale); missing > 0 {
+		if rounded.Scale == 0 {
+			text += "."
+		}
+		text += strings.Repeat("0", missing)
+	}
+	return text
+}
@@ -0,0 +1,87 @@
+package evaluator
+
+import (
+	"testing"
+	"yap/object"
+)
+
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`propose total = 7; propose num = 9; "score: {total}/{num}"`, "score: 7/9"},
+		{`propose i = 2.5; "Question " + "{i}"`, "Question 2.5"},
+		{`"{1 + 2} {nocap} {[1, 2]} {(1, "a")} {{1: 2}}"`, "3 true [1, 2] (1, a) {1: 2}"},
+		{`"{ {"a": 1}["a"] }"`, "1"},
+		{`propose name = "yap"; "{"hello {name}"}!"`, "hello yap!"},
+		{`"{{literal}} {1}}}"`, "{literal} 1}"},
+		{`propose a = 1; "\{a\} {a}"`, "{a} 1"},
+		{`"{{\"key\": {1}}}"`, `{"key": 1}`},
+		{`"{func() { 1 }()}"`, "1"},
+		{`"{len("four")}"`, "4"},
+		{`""`, ""},
+		{`"{"x"}"`, "x"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFormatSpecifiers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"[{42:6}]"`, "[    42]"},
+		{`"[{"ab":6}]"`, "[ab    ]"},
+		{`"[{42:<6}]"`, "[42    ]"},
+		{`"[{"ab":>6}]"`, "[    ab]"},
+		{`"[{"ab":^7}]"`, "[  ab   ]"},
+		{`"[{"ab":*^6}]"`, "[**ab**]"},
+		{`"[{7:03}]"`, "[007]"},
+		{`"[{-7:04}]"`, "[-007]"},
+		{`"[{7:0<3}]"`, "[700]"},
+		{`"[{1.0 / 3:.2}]"`, "[0.33]"},
+		{`"[{2.5:08.3}]"`, "[0002.500]"},
+		{`"[{3:.2}]"`, "[3.00]"},
+		{`"[{1.005d:.2}]"`, "[1.00]"},
+		{`"[{1.5d:.3}]"`, "[1.500]"},
+		{`"[{12345678901234567890:.1}]"`, "[12345678901234567890.0]"},
+		{`"[{"abcdef":.3}]"`, "[abc]"},
+		{`"[{"abcdef":>5.3}]"`, "[  abc]"},
+		{`"[{"longer":2}]"`, "[longer]"},
+		{`"[{nocap:>5}]"`, "[ true]"},
+	}
+
+	for _, tt := range tests {
+		testInspected(t, tt.input, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"{1:x}"`, `invalid format specifier "x"`},
+		{`"{1:>x}"`, `invalid format specifier ">x"`},
+		{`"{1:.}"`, `invalid format specifier "."`},
+		{`"{1:99999}"`, `invalid format specifier "99999"`},
+		{`"{nocap:.2}"`, `format specifier ".2" has a precision, which BOOLEAN does not take`},
+		{`"{missing}"`, "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("%s: no error object returned, got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("%s: wrong error message, expect=%q, got=%q", tt.input, tt.expected, errObj.Message)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"yap/ast"
+	"yap/lexer"
+	"yap/object"
+	"yap/parser"
+)
+
+var (
+	// modules caches every imported file by its absolute path, so each one is evaluated once
+	modules = map[string]*object.Module{}
+	// importing holds the files currently being imported, innermost last
+	importing = []string{}
+)
+
+// resetModules forgets every imported file, so they are evaluated again on the next import
+func resetModules() {
+	modules = map[string]*object.Module{}
+	importing = []string{}
+}
+
+func evalImportStatement(node *ast.ImportStatement, env *object.Enviroment) object.Object {
+	path, err := modulePath(node.Path, env)
+	if err != nil {
+		return withPosition(err, node.Token)
+	}
+	// yoinking the same file under the same name again is fine, taking any other name is not
+	if existing, ok := env.Local(node.Name.Value); ok {
+		if other, isModule := existing.(*object.Module); !isModule || other.Path != path {
+			return withPosition(newError("cannot yoink %s as %s, the name is already taken",
+				filepath.Base(path), node.Name.Value), node.Token)
+		}
+	}
+
+	module := importModule(path, node.Name.Value, env)
+	if isError(module) {
+		return withPosition(module, node.Token)
+	}
+	env.Declare(node.Name.Value, module)
+	return nil
+}
+
+// modulePath makes path absolute, a relative one starts from the folder of the importing file
+func modulePath(path string, env *object.Enviroment) (string, *object.Error) {
+	if !filepath.IsAbs(path) {
+		dir := "."
+		if from := env.File(); from != "" {
+			dir = filepath.Dir(from)
+		}
+		path = filepath.Join(dir, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", newError("could not import %s: %s", path, err)
+	}
+	return abs, nil
+}
+
+// importModule evaluates the file at the absolute path, or takes it from the cache
+func importModule(path, name string, env *object.Enviroment) object.Object {
+	chain := importChain(env)
+	if err := checkImportCycle(path, chain); err != nil {
+		return err
+	}
+	if module, ok := modules[path]; ok {
+		// the same module under the name of this import
+		return &object.Module{Name: name, Path: module.Path, Env: module.Env}
+	}
+
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return newError("could not import %s: %s", filepath.Base(path), err)
+	}
+
+	p := parser.New(lexer.New(string(input)))
+	program := p.ParserProgram()
+	if len(p.Errors()) != 0 {
+		return newError("could not import %s: %s", filepath.Base(path), strings.Join(p.Errors(), "; "))
+	}
+
+	previous := importing
+	importing = append(chain[:len(chain):len(chain)], path)
+	defer func() { importing = previous }()
+
+	moduleEnv := object.NewEnviroment()
+	moduleEnv.SetFile(path)
+	if result := Eval(program, moduleEnv); isError(result) {
+		// the position belongs to the module, so it moves into the message
+		// and the error gets the position of the import instead
+		errObj := result.(*object.Error)
+		return newError("%s:%d:%d: %s", filepath.Base(path), errObj.Line, errObj.Column, errObj.Message)
+	}
+
+	module := &object.Module{Name: name, Path: path, Env: moduleEnv}
+	modules[path] = module
+	return module
+}
+
+// importChain returns the files on the way to the current import,
+// starting with the file that imported the first module
+func importChain(env *object.Enviroment) []string {
+	if len(importing) != 0 || env.File() == "" {
+		return importing
+	}
+	if from, err := filepath.Abs(env.File()); err == nil {
+		return []string{from}
+	}
+	return importing
+}
+
+// checkImportCycle reports an error when path is already somewhere in the chain of imports
+func checkImportCycle(path string, chain []string) *object.Error {
+	for i, file := range chain {
+		if file != path {
+			continue
+		}
+		names := []string{}
+		for _, f := range chain[i:] {
+			names = append(names, filepath.Base(f))
+		}
+		names = append(names, filepath.Base(path))
+		return newError("circular import: %s", strings.Join(names, " -> "))
+	}
+	return nil
+}
+
+func evalMemberExpression(node *ast.MemberExpression, env *object.Enviroment) object.Object {
+	left := Eval(node.Left, env)
+	if isError(left) {
+		return left
+	}
+
+	module, ok := left.(*object.Module)
+	if !ok {
+		return withPosition(newError("member access not supported on %s", left.Type()), node.Token)
+	}
+
+	member, ok := module.Member(node.Member.Value)
+	if !ok {
+		return withPosition(newError("module %s has no exported member %s",
+			module.Name, node.Member.Value), node.Member.Token)
+	}
+	return member
+}
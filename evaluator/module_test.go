@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"yap/lexer"
+	"yap/object"
+	"yap/parser"
+)
+
+func writeModules(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Could not create %s: %s", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Could not write %s: %s", path, err)
+		}
+	}
+	return dir
+}
+
+func testEvalFile(t *testing.T, path string) object.Object {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Could not read %s: %s", path, err)
+	}
+	p := parser.New(lexer.New(string(input)))
+	program := p.ParserProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("Parser errors in %s: %v", path, p.Errors())
+	}
+	env := object.NewEnviroment()
+	env.SetFile(path)
+	Reset()
+	return Eval(program, env)
+}
+
+func TestImportStatement(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"main.yap": `
+            yoink "lib/mathx.yap";
+            yoink "lib/mathx.yap" as again;
+            yoink "./lib/counter.yap" as c;
+            c.bump();
+            again.bump();
+            mathx.square(3) + c.count() + again.pi;
+        `,
+		"lib/mathx.yap": `
+            yoink "counter.yap";
+            propose pi = 3;
+            func square(x) { x * x }
+            func bump() { counter.bump() }
+        `,
+		"lib/counter.yap": `
+            propose _count = 0;
+            func bump() { _count = _count + 1; }
+            func count() { _count }
+        `,
+	})
+
+	// mathx and counter are evaluated once, so both bumps hit the same counter
+	testIntegerObject(t, testEvalFile(t, filepath.Join(dir, "main.yap")), 14)
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"private.yap":  `yoink "lib.yap"; lib._secret`,
+		"missing.yap":  `yoink "lib.yap"; lib.nothing`,
+		"alias.yap":    `yoink "lib.yap"; yoink "lib.yap" as other; other.nothing`,
+		"taken.yap":    `propose lib = 1; yoink "lib.yap";`,
+		"clash.yap":    `yoink "lib.yap" as m; yoink "fails.yap" as m;`,
+		"reassign.yap": `yoink "lib.yap"; lib = 1;`,
+		"notfound.yap": `yoink "nowhere.yap";`,
+		"broken.yap":   `yoink "fails.yap";`,
+		"cycle.yap":    `yoink "a.yap";`,
+		"member.yap":   `propose a = 1; a.b`,
+		"lib.yap":      `propose _secret = 1; propose shown = 2;`,
+		"fails.yap":    "propose a = 1;\npropose b = a + cap;",
+		"a.yap":        `yoink "b.yap";`,
+		"b.yap":        `yoink "cycle.yap";`,
+	})
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"private.yap", "module lib has no exported member _secret"},
+		{"missing.yap", "module lib has no exported member nothing"},
+		{"alias.yap", "module other has no exported member nothing"},
+		{"taken.yap", "cannot yoink lib.yap as lib, the name is already taken"},
+		{"clash.yap", "cannot yoink fails.yap as m, the name is already taken"},
+		{"reassign.yap", "type mismatch error: could not set INTEGER into 'lib' variable (Type = MODULE)"},
+		{"notfound.yap", "could not import nowhere.yap: open " + filepath.Join(dir, "nowhere.yap") + ": no such file or directory"},
+		{"broken.yap", "fails.yap:2:15: type mismatch: INTEGER + BOOLEAN"},
+		{"cycle.yap", "a.yap:1:1: b.yap:1:1: circular import: cycle.yap -> a.yap -> b.yap -> cycle.yap"},
+		{"member.yap", "member access not supported on INTEGER"},
+	}
+
+	for _, test := range tests {
+		eval := testEvalFile(t, filepath.Join(dir, test.file))
+		errObj, ok := eval.(*object.Error)
+		if !ok {
+			t.Errorf("%s: object is not Error, got=%T (%+v)", test.file, eval, eval)
+			continue
+		}
+		if errObj.Message != test.expected {
+			t.Errorf("%s: wrong error message. expect=%s, got=%s", test.file, test.expected, errObj.Message)
+		}
+	}
+}
+
+func TestModuleInspect(t *testing.T) {
+	dir := writeModules(t, map[string]string{
+		"main.yap": `yoink "shapes.yap" as s; s`,
+		"shapes.yap": `
+            propose sides = 4;
+            propose _hidden = 1;
+            func area(w, h) { w * h }
+        `,
+	})
+
+	eval := testEvalFile(t, filepath.Join(dir, "main.yap"))
+	if eval.Inspect() != "module s {area, sides}" {
+		t.Fatalf("Inspect error: expect=%q, got=%q", "module s {area, sides}", eval.Inspect())
+	}
+}
@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 	"yap/token"
 )
 
@@ -9,30 +13,88 @@ type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	ch           byte
+	ch           rune
+	line         int
+	column       int
+	// interpolations has one entry for every `{` of a string interpolation that is still open
+	interpolations []interpolation
+	// brackets are the `(`, `[` and `{` that are still open, the innermost one last
+	brackets []rune
+	// last is the type of the token read last, a new line after one that can end
+	// a statement ends it like a `;`
+	last   token.TokenType
+	errors []string
+}
+
+type interpolation struct {
+	// brackets counts the brackets opened inside the interpolated expression,
+	// so the `}` closing it can be told apart
+	brackets int
+	// multiline is set for the interpolations of a """ string
+	multiline bool
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{input: input, line: 1}
 	l.readChar() //using readChar to initialize the Lexer with pos = 0 and readpos = 1
 	return l
 }
 
 func (l *Lexer) readChar() {
+	// line and column always point at l.ch, so a new line starts once we move past '\n'
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
+	}
+	l.column++
+	l.position = l.readPosition
 	if l.readPosition >= len(l.input) {
 		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+		l.readPosition++
+		return
 	}
-	l.position = l.readPosition
-	l.readPosition++
+	ch, size := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	if ch == utf8.RuneError && size == 1 {
+		l.errorAt(l.line, l.column, "invalid UTF-8 encoding, byte %#x", l.input[l.readPosition])
+	}
+	l.ch = ch
+	l.readPosition += size
+}
+
+// endsStatement are the tokens that can be the last one of a statement. A new line
+// right after one of them is read as a `;`, the same way Go does it
+var endsStatement = map[token.TokenType]bool{
+	token.RETURN:     true,
+	token.IDENT:      true,
+	token.INT:        true,
+	token.FLOAT:      true,
+	token.DECIMAL:    true,
+	token.STRING:     true,
+	token.STRING_END: true,
+	token.TRUE:       true,
+	token.FALSE:      true,
+	token.RPAREN:     true,
+	token.RBRACKET:   true,
+	token.RBRACE:     true,
+	token.INCREMENT:  true,
+	token.DECREMENT:  true,
 }
 
 func (l *Lexer) NextToken() token.Token {
+	tok := l.readToken()
+	l.last = tok.Type
+	return tok
+}
+
+func (l *Lexer) readToken() token.Token {
 	var tok token.Token
 	l.skipWhiteSpace()
+	line, column := l.line, l.column
 
 	switch l.ch {
+	case '\n':
+		// skipWhiteSpace only stops on a new line that ends the statement
+		tok = token.Token{Type: token.SEMICOLON, Literal: "\n"}
 	case '=': //check for '=='
 		if l.nextChar() == '=' {
 			char := l.ch
@@ -45,59 +107,75 @@ func (l *Lexer) NextToken() token.Token {
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case '-':
-		if l.nextChar() == '-' {
-			a := Lexer{input: l.input, position: l.position, readPosition: l.readPosition, ch: l.ch}
-			a.readChar()
-			if a.nextChar() != '-' {
-				a.skipWhiteSpace()
-				if isDigital(a.nextChar()) || isLetter(a.nextChar()) || a.nextChar() == ';' {
-					char := l.ch
-					l.readChar()
-					lit := string(char) + string(l.ch)
-					tok = token.Token{Type: token.DECREMENT, Literal: lit}
-				} else {
-					tok = newToken(token.ILLEGAL, l.ch)
-				}
-			}
+		if l.nextChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: "-="}
+		} else if l.nextChar() == '-' {
+			// `--` is always one token, `a---b` is `a-- - b` and `a - --b` needs the spaces
+			l.readChar()
+			tok = token.Token{Type: token.DECREMENT, Literal: "--"}
 		} else {
 			tok = newToken(token.MINUS, l.ch)
 		}
 	case '(':
+		l.openBracket()
 		tok = newToken(token.LPAREN, l.ch)
 	case ')':
+		l.closeBracket()
 		tok = newToken(token.RPAREN, l.ch)
 	case '{':
+		l.openBracket()
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
-		tok = newToken(token.RBRACE, l.ch)
+		if l.inInterpolation() {
+			// the `}` closing an interpolation goes on with the rest of the string
+			last := l.interpolations[len(l.interpolations)-1]
+			l.interpolations = l.interpolations[:len(l.interpolations)-1]
+			tok = l.readStringPart(token.STRING_END, token.STRING_MIDDLE, last.multiline, line, column)
+		} else {
+			l.closeBracket()
+			tok = newToken(token.RBRACE, l.ch)
+		}
 	case '[':
+		l.openBracket()
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
+		l.closeBracket()
 		tok = newToken(token.RBRACKET, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case '+':
-		if l.nextChar() == '+' {
-			a := Lexer{input: l.input, position: l.position, readPosition: l.readPosition, ch: l.ch}
-			a.readChar()
-			if a.nextChar() != '+' {
-				a.skipWhiteSpace()
-				if isDigital(a.nextChar()) || isLetter(a.nextChar()) || a.nextChar() == ';' {
-					char := l.ch
-					l.readChar()
-					lit := string(char) + string(l.ch)
-					tok = token.Token{Type: token.INCREMENT, Literal: lit}
-				} else {
-					tok = newToken(token.ILLEGAL, l.ch)
-				}
-			}
+		if l.nextChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: "+="}
+		} else if l.nextChar() == '+' {
+			l.readChar()
+			tok = token.Token{Type: token.INCREMENT, Literal: "++"}
 		} else {
 			tok = newToken(token.PLUS, l.ch)
 		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.nextChar() == '>' {
+			l.readChar()
+			tok = token.Token{Type: token.RSHIFT, Literal: ">>"}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.nextChar() == '<' {
+			l.readChar()
+			tok = token.Token{Type: token.LSHIFT, Literal: "<<"}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
+	case '&':
+		tok = newToken(token.AMPERSAND, l.ch)
+	case '|':
+		tok = newToken(token.PIPE, l.ch)
+	case '^':
+		tok = newToken(token.CARET, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
 	case '!': //check for '!='
 		if l.nextChar() == '=' {
 			char := l.ch
@@ -108,7 +186,14 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.BANG, l.ch)
 		}
 	case '*':
-		if l.nextChar() == '*' {
+		if l.nextChar() == '*' && l.nextTwoChar() == '=' {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.POWER_ASSIGN, Literal: "**="}
+		} else if l.nextChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: "*="}
+		} else if l.nextChar() == '*' {
 			char := l.ch
 			l.readChar()
 			literal := string(char) + string(l.ch)
@@ -117,38 +202,80 @@ func (l *Lexer) NextToken() token.Token {
 			tok = newToken(token.ASTERISK, l.ch)
 		}
 	case '/':
-		tok = newToken(token.DASH, l.ch)
+		if l.nextChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.DASH_ASSIGN, Literal: "/="}
+		} else {
+			tok = newToken(token.DASH, l.ch)
+		}
 	case '.':
-		tok = newToken(token.FLOAT, l.ch)
+		if strings.HasPrefix(l.input[l.position:], "...") {
+			l.readChar()
+			l.readChar()
+			tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+		} else if isDigital(l.nextChar()) {
+			tok = l.readNumber(line, column)
+			tok.Line, tok.Column = line, column
+			return tok
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '?':
 		tok = newToken(token.TERNARY, l.ch)
 	case ':':
+		if l.inInterpolation() {
+			tok = l.readFormatSpec()
+			tok.Line, tok.Column = line, column
+			return tok
+		}
 		tok = newToken(token.COLON, l.ch)
 	case '%':
-		tok = newToken(token.MOD, l.ch)
+		if l.nextChar() == '=' {
+			l.readChar()
+			tok = token.Token{Type: token.MOD_ASSIGN, Literal: "%="}
+		} else {
+			tok = newToken(token.MOD, l.ch)
+		}
 	case 0:
 		tok.Literal = ""
 		tok.Type = token.EOF
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
+		multiline := strings.HasPrefix(l.input[l.position:], `"""`)
+		if multiline {
+			l.readChar()
+			l.readChar()
+			// the text starts on the line after the opening quotes
+			if l.nextChar() == '\r' && l.nextTwoChar() == '\n' {
+				l.readChar()
+			}
+			if l.nextChar() == '\n' {
+				l.readChar()
+			}
+		}
+		tok = l.readStringPart(token.STRING, token.STRING_START, multiline, line, column)
+	case '`':
+		tok = l.readRawString(line, column)
 	default:
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Line, tok.Column = line, column
 			return tok
 		} else if isDigital(l.ch) {
-			tok = l.readNumber()
+			tok = l.readNumber(line, column)
+			tok.Line, tok.Column = line, column
 			return tok
 		} else {
+			l.errorAt(line, column, "illegal character %q", l.ch)
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
 	}
 	l.readChar() // move the char up every time this is run
+	tok.Line, tok.Column = line, column
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
@@ -160,79 +287,364 @@ func (l *Lexer) readIdentifier() string {
 	return l.input[postion:l.position] //group up the whole word
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+// IsIdentifier tells whether name would be read as one identifier, like a module
+// name taken from a file name
+func IsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, ch := range name {
+		if !isLetter(ch) {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
 }
 
 func (l *Lexer) skipWhiteSpace() {
 	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+		if l.ch == '\n' && l.newLineEndsStatement() {
+			return
+		}
 		l.readChar()
 	}
 }
 
-func (l *Lexer) nextChar() byte {
+// newLineEndsStatement tells whether the new line at l.ch stands for a `;`. It does
+// after a token that can end a statement, unless the line is inside `(`, `[` or an
+// interpolation, or the next line goes on with a `}`, `perchance` or `otherwise`.
+// A `{` on the next line only goes on after a `)`, where it is the block of
+// something like `perhaps (a)` or `func f()`
+func (l *Lexer) newLineEndsStatement() bool {
+	if !endsStatement[l.last] || len(l.interpolations) > 0 {
+		return false
+	}
+	if len(l.brackets) > 0 && l.brackets[len(l.brackets)-1] != '{' {
+		return false
+	}
+
+	next := strings.TrimLeft(l.input[l.readPosition:], " \t\r\n")
+	if strings.HasPrefix(next, "}") || l.last == token.RPAREN && strings.HasPrefix(next, "{") {
+		return false
+	}
+	for _, word := range []string{"perchance", "otherwise"} {
+		if rest, ok := strings.CutPrefix(next, word); ok {
+			ch, _ := utf8.DecodeRuneInString(rest)
+			if !isLetter(ch) && !isDigital(ch) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// previousChar returns the character before l.ch
+func (l *Lexer) previousChar() rune {
+	ch, _ := utf8.DecodeLastRuneInString(l.input[:l.position])
+	return ch
+}
+
+// nextChar returns the character after l.ch without moving to it
+func (l *Lexer) nextChar() rune {
 	if l.readPosition >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.readPosition]
 	}
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	return ch
 }
 
-func (l *Lexer) readNumber() token.Token {
-	var tok token.Token
+// numberBases maps the letter after the 0 of a prefixed int to the digits it takes
+var numberBases = map[rune]struct {
+	name    string
+	isDigit func(rune) bool
+}{
+	'x': {"hex", isHexDigit},
+	'b': {"binary", func(ch rune) bool { return ch == '0' || ch == '1' }},
+	'o': {"octal", func(ch rune) bool { return '0' <= ch && ch <= '7' }},
+}
+
+// readNumber reads an int like 42, 1_000, 0xFF, 0b1010 or 0o17, a float like
+// 3.14, .5 or 1e-9, or a decimal like 19.99d. A malformed number is reported
+// and given as an ILLEGAL token
+func (l *Lexer) readNumber(line, column int) token.Token {
 	position := l.position
-	flo := false
-	for isDigital(l.ch) {
+	tokType := token.TokenType(token.INT)
+	problem := ""
+	report := func(msg string) {
+		if problem == "" {
+			problem = msg
+		}
+	}
+
+	if base, ok := numberBases[unicode.ToLower(l.nextChar())]; ok && l.ch == '0' {
+		l.readChar()
 		l.readChar()
-		if l.ch == '.' {
-			tok.Type = token.FLOAT
+		if !base.isDigit(l.ch) {
+			report(fmt.Sprintf("expected %s digits after the prefix", base.name))
+		}
+		report(l.readDigits(base.isDigit))
+	} else {
+		if l.ch != '.' {
+			report(l.readDigits(isDigital))
+			if l.input[position] == '0' && l.position-position > 1 && l.ch != '.' && l.ch != 'e' && l.ch != 'E' {
+				report("leading zeros are not allowed, use 0o for an octal number")
+			}
+		}
+		if l.ch == '.' && l.nextChar() != '.' {
+			// `1..` is left to be the int 1 followed by dots
+			tokType = token.FLOAT
+			l.readChar()
+			if !isDigital(l.ch) {
+				report("expected a digit after the point")
+			}
+			report(l.readDigits(isDigital))
+		}
+		exponent := l.ch == 'e' || l.ch == 'E'
+		if exponent {
+			tokType = token.FLOAT
 			l.readChar()
-			flo = !flo
-			if l.ch == '.' {
-				tok.Literal = l.input[position:l.position]
-				return tok
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			if !isDigital(l.ch) {
+				report("expected digits in the exponent")
 			}
+			report(l.readDigits(isDigital))
+		}
+		// a `d` right after the number makes it a decimal, like 19.99d
+		if l.ch == 'd' && !isLetter(l.nextChar()) && !isDigital(l.nextChar()) {
+			if exponent {
+				report("a decimal cannot have an exponent")
+			}
+			tokType = token.DECIMAL
+			l.readChar()
 		}
 	}
-	if !flo {
-		tok.Type = token.INT
+
+	// the rest of something like 12abc or 1.2.3 goes with the number so it is reported once
+	if isLetter(l.ch) || isDigital(l.ch) || l.ch == '.' && isDigital(l.nextChar()) {
+		report(fmt.Sprintf("unexpected %q", l.ch))
+		for isLetter(l.ch) || isDigital(l.ch) || l.ch == '.' && isDigital(l.nextChar()) {
+			l.readChar()
+		}
 	}
-	tok.Literal = l.input[position:l.position]
-	return tok
+
+	literal := l.input[position:l.position]
+	if problem != "" {
+		l.errorAt(line, column, "malformed number %s: %s", literal, problem)
+		return token.Token{Type: token.ILLEGAL, Literal: literal}
+	}
+	return token.Token{Type: tokType, Literal: literal}
 }
 
-func isDigital(ch byte) bool {
+// readDigits reads digits that can be split by single underscores like 1_000,
+// it returns what is wrong with them or an empty string
+func (l *Lexer) readDigits(isDigit func(rune) bool) string {
+	problem := ""
+	for {
+		if isDigit(l.ch) {
+			l.readChar()
+		} else if l.ch == '_' {
+			if !isDigit(l.nextChar()) || !isDigit(l.previousChar()) {
+				problem = "`_` must be between two digits"
+			}
+			l.readChar()
+		} else {
+			return problem
+		}
+	}
+}
+
+func isDigital(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func (l *Lexer) nextTwoChar() byte {
-	if l.readPosition+1 >= len(l.input) {
+func (l *Lexer) nextTwoChar() rune {
+	if l.readPosition >= len(l.input) {
+		return 0
+	}
+	_, size := utf8.DecodeRuneInString(l.input[l.readPosition:])
+	if l.readPosition+size >= len(l.input) {
 		return 0
-	} else {
-		return l.input[l.readPosition+1]
 	}
+	ch, _ := utf8.DecodeRuneInString(l.input[l.readPosition+size:])
+	return ch
 }
 
-func (l *Lexer) readString() string {
-	l.readChar()
-	str := []string{}
-	str = append(str, string(l.ch))
-	for l.ch != '"' && l.ch != 0 {
+// readStringPart reads the text of a string from the opening `"`, or from the `}`
+// closing an interpolation, up to the closing `"` which gives a token of type end,
+// or up to the `{` of the next interpolation which gives a token of type open.
+// `{{` and `}}`, like `\{` and `\}`, stand for the braces themselves. Only a multiline string, the
+// one between """, can hold a new line. line and column are where the part starts
+func (l *Lexer) readStringPart(end, open token.TokenType, multiline bool, line, column int) token.Token {
+	var str strings.Builder
+	for {
 		l.readChar()
-		if l.ch == '\\' {
-			if l.nextChar() == 't' {
+		switch l.ch {
+		case 0:
+			l.errorAt(line, column, "unterminated string")
+			return token.Token{Type: end, Literal: str.String()}
+		case '\n':
+			if !multiline {
+				l.errorAt(line, column, "unterminated string, use \\n or a \"\"\" string for a new line")
+				return token.Token{Type: end, Literal: str.String()}
+			}
+			str.WriteByte('\n')
+		case '"':
+			if !multiline {
+				return token.Token{Type: end, Literal: str.String()}
+			}
+			if strings.HasPrefix(l.input[l.position:], `"""`) {
+				l.readChar()
 				l.readChar()
-				str = append(str, "\t")
-			} else if l.nextChar() == 'n' {
+				return token.Token{Type: end, Literal: str.String()}
+			}
+			str.WriteByte('"')
+		case '{':
+			if l.nextChar() != '{' {
+				l.interpolations = append(l.interpolations, interpolation{multiline: multiline})
+				return token.Token{Type: open, Literal: str.String()}
+			}
+			l.readChar()
+			str.WriteByte('{')
+		case '}':
+			if l.nextChar() == '}' {
 				l.readChar()
-				str = append(str, "\n")
-			} else {
-				str = append(str, string(l.ch))
 			}
+			str.WriteByte('}')
+		case '\\':
+			l.readEscape(&str)
+		default:
+			str.WriteRune(l.ch)
+		}
+	}
+}
+
+// escapes maps the character after a backslash to the one it stands for
+var escapes = map[rune]rune{'n': '\n', 't': '\t', 'r': '\r', '0': 0, '"': '"', '\\': '\\', '{': '{', '}': '}'}
+
+// readEscape reads the escape sequence whose backslash is l.ch and writes the
+// character it stands for. An invalid one is reported and left out
+func (l *Lexer) readEscape(str *strings.Builder) {
+	line, column := l.line, l.column
+	if ch, ok := escapes[l.nextChar()]; ok {
+		l.readChar()
+		str.WriteRune(ch)
+		return
+	}
+
+	switch l.nextChar() {
+	case 'x':
+		l.readChar()
+		digits := l.readHexDigits(2)
+		value, _ := strconv.ParseUint(digits, 16, 8)
+		if len(digits) != 2 {
+			l.errorAt(line, column, "invalid escape sequence \\x%s, expected 2 hex digits", digits)
+		} else if value > 0x7F {
+			l.errorAt(line, column, "invalid escape sequence \\x%s, it is not ASCII, use \\u{%s}", digits, digits)
 		} else {
-			str = append(str, string(l.ch))
+			str.WriteByte(byte(value))
+		}
+	case 'u':
+		l.readChar()
+		if l.nextChar() != '{' {
+			l.errorAt(line, column, "invalid escape sequence \\u, expected \\u{...} with 1 to 6 hex digits")
+			return
+		}
+		l.readChar()
+		digits := l.readHexDigits(6)
+		if len(digits) == 0 || l.nextChar() != '}' {
+			l.errorAt(line, column, "invalid escape sequence \\u{%s, expected \\u{...} with 1 to 6 hex digits", digits)
+			return
+		}
+		l.readChar()
+		value, _ := strconv.ParseUint(digits, 16, 32)
+		if !utf8.ValidRune(rune(value)) {
+			l.errorAt(line, column, "invalid escape sequence \\u{%s}, it is not a valid character", digits)
+			return
+		}
+		str.WriteRune(rune(value))
+	case 0, '\n':
+		l.errorAt(line, column, "invalid escape sequence, the string ends after the \\")
+	default:
+		l.errorAt(line, column, "invalid escape sequence \\%c", l.nextChar())
+	}
+}
+
+// readHexDigits reads up to max hex digits following l.ch
+func (l *Lexer) readHexDigits(max int) string {
+	position := l.readPosition
+	for i := 0; i < max && isHexDigit(l.nextChar()); i++ {
+		l.readChar()
+	}
+	return l.input[position:l.readPosition]
+}
+
+func isHexDigit(ch rune) bool {
+	return isDigital(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+// readRawString reads a string between backquotes, it can span lines and
+// nothing in it is escaped or interpolated
+func (l *Lexer) readRawString(line, column int) token.Token {
+	position := l.position + 1
+	for {
+		l.readChar()
+		if l.ch == '`' {
+			return token.Token{Type: token.STRING, Literal: l.input[position:l.position]}
 		}
+		if l.ch == 0 {
+			l.errorAt(line, column, "unterminated raw string")
+			return token.Token{Type: token.STRING, Literal: l.input[position:l.position]}
+		}
+	}
+}
+
+// Errors returns the problems found while reading the input, like an invalid
+// escape sequence, each one starts with the line and column it is at
+func (l *Lexer) Errors() []string {
+	return l.errors
+}
+
+func (l *Lexer) errorAt(line, column int, format string, args ...interface{}) {
+	msg := fmt.Sprintf("%d:%d: ", line, column) + fmt.Sprintf(format, args...)
+	l.errors = append(l.errors, msg)
+}
+
+// readFormatSpec reads what follows the `:` of an interpolation like `{x:>8}`,
+// it stops on the `}` so that it is read as the end of the interpolation
+func (l *Lexer) readFormatSpec() token.Token {
+	l.readChar()
+	position := l.position
+	for l.ch != '}' && l.ch != '"' && l.ch != 0 {
+		l.readChar()
+	}
+	return token.Token{Type: token.FORMAT_SPEC, Literal: l.input[position:l.position]}
+}
+
+// inInterpolation tells whether the lexer is at the top level of an interpolated
+// expression, where a `:` starts the format and a `}` ends the expression
+func (l *Lexer) inInterpolation() bool {
+	return len(l.interpolations) > 0 && l.interpolations[len(l.interpolations)-1].brackets == 0
+}
+
+func (l *Lexer) openBracket() {
+	l.brackets = append(l.brackets, l.ch)
+	if len(l.interpolations) > 0 {
+		l.interpolations[len(l.interpolations)-1].brackets++
+	}
+}
+
+func (l *Lexer) closeBracket() {
+	if len(l.brackets) > 0 {
+		l.brackets = l.brackets[:len(l.brackets)-1]
+	}
+	if len(l.interpolations) > 0 && l.interpolations[len(l.interpolations)-1].brackets > 0 {
+		l.interpolations[len(l.interpolations)-1].brackets--
 	}
-	str = str[:len(str)-1] //removing the last "
-	return strings.Join(str, "")
 }
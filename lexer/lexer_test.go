@@ -122,6 +122,7 @@ func TestNextToken(t *testing.T) {
 		{token.IDENT, "ten"},
 		{token.SEMICOLON, ";"},
 		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
 		{token.INT, "5"},
 		{token.EQ, "=="},
 		{token.INT, "5"},
@@ -176,3 +177,540 @@ func TestString(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPosition(t *testing.T) {
+	input := "propose a = 5;\n\tyap(a,\n  \"hi\");"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+	}{
+		{token.LET, 1, 1},
+		{token.IDENT, 1, 9},
+		{token.ASSIGN, 1, 11},
+		{token.INT, 1, 13},
+		{token.SEMICOLON, 1, 14},
+		{token.IDENT, 2, 2},
+		{token.LPAREN, 2, 5},
+		{token.IDENT, 2, 6},
+		{token.COMMA, 2, 7},
+		{token.STRING, 3, 3},
+		{token.RPAREN, 3, 7},
+		{token.SEMICOLON, 3, 8},
+		{token.EOF, 3, 9},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType {
+			t.Fatalf("tests[%d], expected=%q, got=%q", i, test.expectedType, tok.Type)
+		}
+		if tok.Line != test.expectedLine || tok.Column != test.expectedColumn {
+			t.Fatalf("tests[%d] position error: expect=%d:%d, got=%d:%d", i,
+				test.expectedLine, test.expectedColumn, tok.Line, tok.Column)
+		}
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	input := "[a, ...rest] a.b"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.LBRACKET, "["},
+		{token.IDENT, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.RBRACKET, "]"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"a{x}b{f("c{y}"):>4}{{d}}" {1}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.STRING_START, "a"},
+		{token.IDENT, "x"},
+		{token.STRING_MIDDLE, "b"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.STRING_START, "c"},
+		{token.IDENT, "y"},
+		{token.STRING_END, ""},
+		{token.RPAREN, ")"},
+		{token.FORMAT_SPEC, ">4"},
+		{token.STRING_END, "{d}"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\tb\nc\rd"`, "a\tb\nc\rd"},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"nul\0"`, "nul\x00"},
+		{`"\x41\x7f"`, "A\x7f"},
+		{`"\u{e9}\u{1F600}\u{0041}"`, "é😀A"},
+		{`"\{a\} {{b}}"`, "{a} {b}"},
+		{`"\{\"json\": 1\}"`, `{"json": 1}`},
+		{"`raw \\n {x} \"q\"`", `raw \n {x} "q"`},
+		{"`two\nlines`", "two\nlines"},
+		{"\"\"\"\nfirst \"quoted\"\nsecond\n\"\"\"", "first \"quoted\"\nsecond\n"},
+		{"\"\"\"one line\"\"\"", "one line"},
+		{`""`, ""},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if len(l.Errors()) != 0 {
+			t.Errorf("%s: unexpected errors %v", tt.input, l.Errors())
+		}
+		if tok.Type != token.STRING || tok.Literal != tt.expected {
+			t.Errorf("%s: expected=STRING %q, got=%s %q", tt.input, tt.expected, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%s: expected EOF after the string, got=%s %q", tt.input, next.Type, next.Literal)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"bad \q"`, `1:6: invalid escape sequence \q`},
+		{`"\xZ1"`, `1:2: invalid escape sequence \x, expected 2 hex digits`},
+		{`"\xff"`, `1:2: invalid escape sequence \xff, it is not ASCII, use \u{ff}`},
+		{`"\u41"`, `1:2: invalid escape sequence \u, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u{41"`, `1:2: invalid escape sequence \u{41, expected \u{...} with 1 to 6 hex digits`},
+		{`"\u{D800}"`, `1:2: invalid escape sequence \u{D800}, it is not a valid character`},
+		{`"open`, "1:1: unterminated string"},
+		{"propose a = \"open\nb", "1:13: unterminated string, use \\n or a \"\"\" string for a new line"},
+		{"\"\"\"never closed\n", "1:1: unterminated string"},
+		{"`raw", "1:1: unterminated raw string"},
+		{`"a{x}b`, "1:5: unterminated string"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+		if len(l.Errors()) == 0 || l.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, l.Errors())
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := "propose café = \"日本\"; 名前 _x"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedColumn  int
+	}{
+		{token.LET, "propose", 1},
+		{token.IDENT, "café", 9},
+		{token.ASSIGN, "=", 14},
+		{token.STRING, "日本", 16},
+		{token.SEMICOLON, ";", 20},
+		{token.IDENT, "名前", 22},
+		{token.IDENT, "_x", 25},
+		{token.EOF, "", 27},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Column != test.expectedColumn {
+			t.Fatalf("tests[%d] column error: expect=%d, got=%d", i, test.expectedColumn, tok.Column)
+		}
+	}
+	if len(l.Errors()) != 0 {
+		t.Fatalf("unexpected errors %v", l.Errors())
+	}
+
+	l = New("propose a = \"b\xffc\";")
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+	if len(l.Errors()) != 1 || l.Errors()[0] != "1:15: invalid UTF-8 encoding, byte 0xff" {
+		t.Fatalf("expected an encoding error, got=%v", l.Errors())
+	}
+
+	l = New("a 😀 b")
+	l.NextToken()
+	if tok := l.NextToken(); tok.Type != token.ILLEGAL || tok.Literal != "😀" {
+		t.Fatalf("expected the emoji to be ILLEGAL, got=%q %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"42", token.INT, "42"},
+		{"1_000", token.INT, "1_000"},
+		{"0xDead_Beef", token.INT, "0xDead_Beef"},
+		{"0b1010", token.INT, "0b1010"},
+		{"0o755", token.INT, "0o755"},
+		{"0x1d", token.INT, "0x1d"},
+		{"3.14", token.FLOAT, "3.14"},
+		{".5", token.FLOAT, ".5"},
+		{"1e9", token.FLOAT, "1e9"},
+		{"6.02E+23", token.FLOAT, "6.02E+23"},
+		{"1e-9", token.FLOAT, "1e-9"},
+		{"19.99d", token.DECIMAL, "19.99d"},
+		{"1_000d", token.DECIMAL, "1_000d"},
+		{"0", token.INT, "0"},
+		{"0.5", token.FLOAT, "0.5"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("%s: expected=%q %q, got=%q %q", tt.input,
+				tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if next := l.NextToken(); next.Type != token.EOF {
+			t.Errorf("%s: expected EOF after the number, got=%q %q", tt.input, next.Type, next.Literal)
+		}
+		if len(l.Errors()) != 0 {
+			t.Errorf("%s: unexpected errors %v", tt.input, l.Errors())
+		}
+	}
+
+	l := New("1..")
+	for _, expected := range []token.TokenType{token.INT, token.DOT, token.DOT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("1..: expected=%q, got=%q %q", expected, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestMalformedNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0x", "1:1: malformed number 0x: expected hex digits after the prefix"},
+		{"0b", "1:1: malformed number 0b: expected binary digits after the prefix"},
+		{"0b102", "1:1: malformed number 0b102: unexpected '2'"},
+		{"0o8", "1:1: malformed number 0o8: expected octal digits after the prefix"},
+		{"0x_1", "1:1: malformed number 0x_1: expected hex digits after the prefix"},
+		{"1_", "1:1: malformed number 1_: `_` must be between two digits"},
+		{"1__0", "1:1: malformed number 1__0: `_` must be between two digits"},
+		{"1_.5", "1:1: malformed number 1_.5: `_` must be between two digits"},
+		{"007", "1:1: malformed number 007: leading zeros are not allowed, use 0o for an octal number"},
+		{"1.", "1:1: malformed number 1.: expected a digit after the point"},
+		{"1.x", "1:1: malformed number 1.x: expected a digit after the point"},
+		{"1e", "1:1: malformed number 1e: expected digits in the exponent"},
+		{"1e+", "1:1: malformed number 1e+: expected digits in the exponent"},
+		{"1e3d", "1:1: malformed number 1e3d: a decimal cannot have an exponent"},
+		{"12abc", "1:1: malformed number 12abc: unexpected 'a'"},
+		{"a = 3.5.5", "1:5: malformed number 3.5.5: unexpected '.'"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		var illegal bool
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+			illegal = illegal || tok.Type == token.ILLEGAL
+		}
+		if !illegal {
+			t.Errorf("%s: expected an ILLEGAL token", tt.input)
+		}
+		if len(l.Errors()) == 0 || l.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, l.Errors())
+		}
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	input := "a & b | c ^ ~d << 2 >> 1 < >"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.AMPERSAND, "&"},
+		{token.IDENT, "b"},
+		{token.PIPE, "|"},
+		{token.IDENT, "c"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.IDENT, "d"},
+		{token.LSHIFT, "<<"},
+		{token.INT, "2"},
+		{token.RSHIFT, ">>"},
+		{token.INT, "1"},
+		{token.LT, "<"},
+		{token.GT, ">"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	input := "a += 1; a -= 1; a *= 2; a /= 2; a %= 3; a **= 2; a ** 2; a++;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "1"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.DASH_ASSIGN, "/="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.MOD_ASSIGN, "%="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.POWER_ASSIGN, "**="},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.POWER, "**"},
+		{token.INT, "2"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.INCREMENT, "++"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestIncrementDecrement(t *testing.T) {
+	input := "f(a++) a---b ++x[0] y--;"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.IDENT, "a"},
+		{token.INCREMENT, "++"},
+		{token.RPAREN, ")"},
+		{token.IDENT, "a"},
+		{token.DECREMENT, "--"},
+		{token.MINUS, "-"},
+		{token.IDENT, "b"},
+		{token.INCREMENT, "++"},
+		{token.IDENT, "x"},
+		{token.LBRACKET, "["},
+		{token.INT, "0"},
+		{token.RBRACKET, "]"},
+		{token.IDENT, "y"},
+		{token.DECREMENT, "--"},
+		{token.SEMICOLON, ";"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected errors: %v", l.Errors())
+	}
+}
+
+func TestNewLineSemicolons(t *testing.T) {
+	input := `a = 1
+b++
+f(x,
+  y)
+c +
+  d
+perhaps (e) {
+  "s"
+}
+otherwise
+{
+  g
+}
+[h,
+  i]
+"x {j}"
+`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.ASSIGN, "="},
+		{token.INT, "1"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "b"},
+		{token.INCREMENT, "++"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.IDENT, "x"},
+		{token.COMMA, ","},
+		{token.IDENT, "y"},
+		{token.RPAREN, ")"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "c"},
+		{token.PLUS, "+"},
+		{token.IDENT, "d"},
+		{token.SEMICOLON, "\n"},
+		{token.IF, "perhaps"},
+		{token.LPAREN, "("},
+		{token.IDENT, "e"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.STRING, "s"},
+		{token.RBRACE, "}"},
+		{token.ELSE, "otherwise"},
+		{token.LBRACE, "{"},
+		{token.IDENT, "g"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.LBRACKET, "["},
+		{token.IDENT, "h"},
+		{token.COMMA, ","},
+		{token.IDENT, "i"},
+		{token.RBRACKET, "]"},
+		{token.SEMICOLON, "\n"},
+		{token.STRING_START, "x "},
+		{token.IDENT, "j"},
+		{token.STRING_END, ""},
+		{token.SEMICOLON, "\n"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected errors: %v", l.Errors())
+	}
+}
+
+func TestNewLineAfterReturnAndBeforeBrace(t *testing.T) {
+	input := `sayless
+a
+{1}
+f()
+{
+  sayless
+}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.RETURN, "sayless"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "a"},
+		{token.SEMICOLON, "\n"},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.SEMICOLON, "\n"},
+		{token.IDENT, "f"},
+		{token.LPAREN, "("},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.RETURN, "sayless"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+	for i, test := range tests {
+		tok := l.NextToken()
+		if tok.Type != test.expectedType || tok.Literal != test.expectedLiteral {
+			t.Fatalf("tests[%d], expected=%q %q, got=%q %q", i,
+				test.expectedType, test.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	//"os/user"
@@ -10,6 +11,7 @@ import (
 	"yap/lexer"
 	"yap/object"
 	"yap/parser"
+	"yap/tester"
 	//"yap/repl"
 )
 
@@ -33,6 +35,9 @@ func main() {
 		}*/
 
 	args := os.Args
+	if len(args) >= 2 && args[1] == "test" {
+		os.Exit(runTests(args[2:]))
+	}
 	if len(args) != 2 {
 		fmt.Println("Please enter a file you want to interpret")
 		os.Exit(1)
@@ -45,8 +50,8 @@ func main() {
 		fmt.Printf("Please provide a file and not %s\n", fileName)
 		os.Exit(1)
 	}
-	if fileType[1] != "txt" {
-		fmt.Printf("Please provide a '.txt' file, not .%s", fileType)
+	if fileType[1] != "txt" && fileType[1] != "yap" {
+		fmt.Printf("Please provide a '.txt' or '.yap' file, not .%s\n", fileType[1])
 		os.Exit(1)
 	}
 
@@ -62,7 +67,7 @@ func main() {
 		lines = append(lines, sc.Text())
 	}
 
-	l := lexer.New(strings.Join(lines, " "))
+	l := lexer.New(strings.Join(lines, "\n"))
 	p := parser.New(l)
 	program := p.ParserProgram()
 	if len(p.Errors()) != 0 {
@@ -72,9 +77,33 @@ func main() {
 		os.Exit(3)
 	}
 	env := object.NewEnviroment()
+	env.SetFile(fileName)
 	eval := evaluator.Eval(program, env)
-	if eval != nil {
+	if errObj, ok := eval.(*object.Error); ok && errObj.Line != 0 {
+		fmt.Printf("%s:%d:%d: %s\n", fileName, errObj.Line, errObj.Column, errObj.Inspect())
+	} else if eval != nil {
 		fmt.Println(eval.Inspect())
 	}
+}
+
+// runTests handles `yap test [-run pattern] [paths...]` and returns the exit code
+func runTests(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	filter := flags.String("run", "", "only run tests whose name matches this regular expression")
+	flags.Parse(args)
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
 
+	ok, err := tester.Run(os.Stdout, paths, *filter)
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+	if !ok {
+		return 1
+	}
+	return 0
 }
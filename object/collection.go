@@ -0,0 +1,87 @@
+package object
+
+import "strings"
+
+// Set holds hashable elements without duplicates, in the order they were first added
+type Set struct {
+	Elements map[HashKey]Object
+	Order    []Object
+}
+
+func NewSet() *Set {
+	return &Set{Elements: make(map[HashKey]Object), Order: []Object{}}
+}
+
+// Add adds element and reports whether it was missing
+func (s *Set) Add(element Hashable) bool {
+	key := element.HashKey()
+	if _, ok := s.Elements[key]; ok {
+		return false
+	}
+	s.Elements[key] = element.(Object)
+	s.Order = append(s.Order, element.(Object))
+	return true
+}
+
+// Remove removes element and reports whether it was there
+func (s *Set) Remove(element Hashable) bool {
+	key := element.HashKey()
+	if _, ok := s.Elements[key]; !ok {
+		return false
+	}
+	delete(s.Elements, key)
+	for i, e := range s.Order {
+		if e.(Hashable).HashKey() == key {
+			s.Order = append(s.Order[:i:i], s.Order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (s *Set) Has(element Hashable) bool {
+	_, ok := s.Elements[element.HashKey()]
+	return ok
+}
+
+func (s *Set) Type() ObjectType {
+	return SET_OBJ
+}
+
+// Inspect shows an empty set as set() since {} is an empty hash
+func (s *Set) Inspect() string {
+	if len(s.Order) == 0 {
+		return "set()"
+	}
+	return "{" + inspectAll(s.Order) + "}"
+}
+
+// Tuple is a fixed list of values that cannot be changed. It is hashable
+// when all of its elements are, see AsHashable
+type Tuple struct {
+	Elements []Object
+}
+
+func (t *Tuple) Type() ObjectType {
+	return TUPLE_OBJ
+}
+
+// Inspect writes a tuple of one element as (x,) so it does not read like a grouped value
+func (t *Tuple) Inspect() string {
+	if len(t.Elements) == 1 {
+		return "(" + t.Elements[0].Inspect() + ",)"
+	}
+	return "(" + inspectAll(t.Elements) + ")"
+}
+
+func (t *Tuple) HashKey() HashKey {
+	return compositeHashKey(t.Type(), t.Elements)
+}
+
+func inspectAll(elements []Object) string {
+	inspected := make([]string, len(elements))
+	for i, element := range elements {
+		inspected[i] = element.Inspect()
+	}
+	return strings.Join(inspected, ", ")
+}
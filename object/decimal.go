@@ -0,0 +1,228 @@
+package object
+
+import (
+	"hash/fnv"
+	"math/big"
+	"strings"
+)
+
+const DECIMAL_OBJ = "DECIMAL"
+
+type RoundingMode string
+
+const (
+	ROUND_HALF_EVEN RoundingMode = "half_even"
+	ROUND_HALF_UP   RoundingMode = "half_up"
+	ROUND_HALF_DOWN RoundingMode = "half_down"
+	ROUND_UP        RoundingMode = "up"
+	ROUND_DOWN      RoundingMode = "down"
+	ROUND_CEILING   RoundingMode = "ceiling"
+	ROUND_FLOOR     RoundingMode = "floor"
+)
+
+var RoundingModes = []RoundingMode{
+	ROUND_HALF_EVEN, ROUND_HALF_UP, ROUND_HALF_DOWN, ROUND_UP, ROUND_DOWN, ROUND_CEILING, ROUND_FLOOR,
+}
+
+var bigTen = big.NewInt(10)
+
+// Decimal is an exact base 10 number worth Coef / 10^Scale. Addition, subtraction
+// and multiplication never round, only division and Round do. The scale is kept,
+// so 1.50d stays 1.50d, but it does not matter for comparing and hashing
+type Decimal struct {
+	Coef  *big.Int
+	Scale int32
+}
+
+// ParseDecimal reads a number like "-12.50", it reports false for anything else
+func ParseDecimal(s string) (*Decimal, bool) {
+	digits := s
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return nil, false
+	}
+	for _, part := range []string{whole, frac} {
+		for _, ch := range part {
+			if ch < '0' || ch > '9' {
+				return nil, false
+			}
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(whole+frac, 10)
+	if !ok {
+		return nil, false
+	}
+	if strings.HasPrefix(s, "-") {
+		coef.Neg(coef)
+	}
+	return &Decimal{Coef: coef, Scale: int32(len(frac))}, true
+}
+
+func NewDecimalFromInt(value *big.Int) *Decimal {
+	return &Decimal{Coef: new(big.Int).Set(value), Scale: 0}
+}
+
+func (d *Decimal) Type() ObjectType {
+	return DECIMAL_OBJ
+}
+
+func (d *Decimal) Inspect() string {
+	digits := new(big.Int).Abs(d.Coef).String()
+	if d.Scale > 0 {
+		if len(digits) <= int(d.Scale) {
+			digits = strings.Repeat("0", int(d.Scale)-len(digits)+1) + digits
+		}
+		point := len(digits) - int(d.Scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if d.Coef.Sign() < 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+func (d *Decimal) HashKey() HashKey {
+	normalized := d.normalize()
+	if normalized.Scale == 0 {
+		return integerHashKey(normalized.Coef)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(normalized.Inspect()))
+	return HashKey{Type: d.Type(), Value: h.Sum64()}
+}
+
+// normalize drops the trailing zeros of the fraction
+func (d *Decimal) normalize() *Decimal {
+	coef := new(big.Int).Set(d.Coef)
+	scale := d.Scale
+	rem := new(big.Int)
+	for scale > 0 {
+		quo, r := new(big.Int).QuoRem(coef, bigTen, rem)
+		if r.Sign() != 0 {
+			break
+		}
+		coef = quo
+		scale--
+	}
+	return &Decimal{Coef: coef, Scale: scale}
+}
+
+// rescale returns the coefficient of d at a scale that is not smaller than its own
+func (d *Decimal) rescale(scale int32) *big.Int {
+	if scale == d.Scale {
+		return d.Coef
+	}
+	return new(big.Int).Mul(d.Coef, pow10(scale-d.Scale))
+}
+
+func (d *Decimal) Add(other *Decimal) *Decimal {
+	scale := max(d.Scale, other.Scale)
+	return &Decimal{Coef: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), Scale: scale}
+}
+
+func (d *Decimal) Sub(other *Decimal) *Decimal {
+	scale := max(d.Scale, other.Scale)
+	return &Decimal{Coef: new(big.Int).Sub(d.rescale(scale), other.rescale(scale)), Scale: scale}
+}
+
+func (d *Decimal) Mul(other *Decimal) *Decimal {
+	return &Decimal{Coef: new(big.Int).Mul(d.Coef, other.Coef), Scale: d.Scale + other.Scale}
+}
+
+func (d *Decimal) Neg() *Decimal {
+	return &Decimal{Coef: new(big.Int).Neg(d.Coef), Scale: d.Scale}
+}
+
+// Quo divides with at most precision digits after the point, trailing zeros are
+// dropped unless one of the operands had them. other must not be zero
+func (d *Decimal) Quo(other *Decimal, precision int32, mode RoundingMode) *Decimal {
+	// d / other = d.Coef * 10^other.Scale / (other.Coef * 10^d.Scale)
+	num := new(big.Int).Mul(d.Coef, pow10(other.Scale+precision))
+	den := new(big.Int).Mul(other.Coef, pow10(d.Scale))
+	result := (&Decimal{Coef: roundQuo(num, den, mode), Scale: precision}).normalize()
+
+	keep := min(max(d.Scale, other.Scale), precision)
+	if result.Scale < keep {
+		result = &Decimal{Coef: result.rescale(keep), Scale: keep}
+	}
+	return result
+}
+
+// Rem is the remainder of a truncated division, with the sign of d. other must not be zero
+func (d *Decimal) Rem(other *Decimal) *Decimal {
+	scale := max(d.Scale, other.Scale)
+	return &Decimal{Coef: new(big.Int).Rem(d.rescale(scale), other.rescale(scale)), Scale: scale}
+}
+
+// Round rounds d to places digits after the point, a negative places rounds to tens, hundreds...
+func (d *Decimal) Round(places int32, mode RoundingMode) *Decimal {
+	if d.Scale <= places {
+		return d
+	}
+	coef := roundQuo(d.Coef, pow10(d.Scale-places), mode)
+	if places < 0 {
+		return &Decimal{Coef: coef.Mul(coef, pow10(-places)), Scale: 0}
+	}
+	return &Decimal{Coef: coef, Scale: places}
+}
+
+func (d *Decimal) Cmp(other *Decimal) int {
+	scale := max(d.Scale, other.Scale)
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (d *Decimal) Sign() int {
+	return d.Coef.Sign()
+}
+
+// Int returns the whole part of d, the fraction is cut off
+func (d *Decimal) Int() *big.Int {
+	return new(big.Int).Quo(d.Coef, pow10(d.Scale))
+}
+
+func (d *Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.Coef, pow10(d.Scale)).Float64()
+	return f
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
+
+// roundQuo divides num by den and rounds the result with mode
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	sign := int64(num.Sign() * den.Sign())
+	// half tells whether the dropped part is below, at or above one half
+	half := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).CmpAbs(den)
+
+	away := false
+	switch mode {
+	case ROUND_UP:
+		away = true
+	case ROUND_DOWN:
+		away = false
+	case ROUND_CEILING:
+		away = sign > 0
+	case ROUND_FLOOR:
+		away = sign < 0
+	case ROUND_HALF_UP:
+		away = half >= 0
+	case ROUND_HALF_DOWN:
+		away = half > 0
+	default:
+		away = half > 0 || (half == 0 && quo.Bit(0) == 1)
+	}
+	if away {
+		quo.Add(quo, big.NewInt(sign))
+	}
+	return quo
+}
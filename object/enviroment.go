@@ -13,6 +13,20 @@ func NewEnviroment() *Enviroment {
 type Enviroment struct {
 	store map[string]Object
 	outer *Enviroment
+	file  string
+}
+
+// SetFile records the source file evaluated in this enviroment, imports are resolved relative to it
+func (e *Enviroment) SetFile(path string) {
+	e.file = path
+}
+
+// File returns the source file of the closest enviroment that has one
+func (e *Enviroment) File() string {
+	if e.file == "" && e.outer != nil {
+		return e.outer.File()
+	}
+	return e.file
 }
 
 func (e *Enviroment) Get(name string) (Object, bool) {
@@ -24,47 +38,65 @@ func (e *Enviroment) Get(name string) (Object, bool) {
 	return obj, ok
 }
 
+// Set updates name in the closest enviroment that has it, or binds it here when none does
 func (e *Enviroment) Set(name string, val Object) Object {
-	_, ok := e.store[name]
-	if !ok {
-		outer := e.outer
-		if outer != nil {
-			_, ok := outer.store[name]
-			if ok {
-				outer.store[name] = val
-			} else {
-				e.store[name] = val
-			}
-		} else {
-			e.store[name] = val
-		}
+	if owner := e.owner(name); owner != nil {
+		owner.store[name] = val
 	} else {
 		e.store[name] = val
 	}
 	return val
 }
 
-func (e *Enviroment) Exist(name string) bool {
-	_, ok := e.store[name]
-	if !ok {
-		outer := e.outer
-		if outer != nil {
-			_, ok := outer.store[name]
-			return ok
+// Local returns the binding of name in this enviroment only, outer ones are not looked at
+func (e *Enviroment) Local(name string) (Object, bool) {
+	obj, ok := e.store[name]
+	return obj, ok
+}
+
+// Declare binds name in this enviroment only, shadowing any outer binding
+func (e *Enviroment) Declare(name string, val Object) Object {
+	e.store[name] = val
+	return val
+}
+
+// Copy returns an enviroment with the same outer and a copy of the bindings,
+// changing a binding in one does not change it in the other
+func (e *Enviroment) Copy() *Enviroment {
+	env := NewEncloseEnviroment(e.outer)
+	for name, val := range e.store {
+		env.store[name] = val
+	}
+	env.file = e.file
+	return env
+}
+
+// owner returns the closest enviroment binding name, nil when there is none
+func (e *Enviroment) owner(name string) *Enviroment {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			return env
 		}
 	}
-	return ok
+	return nil
+}
+
+func (e *Enviroment) Exist(name string) bool {
+	return e.owner(name) != nil
 }
 
 func (e *Enviroment) TypeComp(name string, valType ObjectType) bool {
-	val := e.store[name]
+	val := e.GetType(name)
 	if val == nil {
 		return true
 	}
 	ident := val.Type()
-	return ident == valType
+	return ident == valType || (IsInteger(ident) && IsInteger(valType))
 }
 
 func (e *Enviroment) GetType(name string) Object {
-	return e.store[name]
+	if owner := e.owner(name); owner != nil {
+		return owner.store[name]
+	}
+	return nil
 }
@@ -0,0 +1,90 @@
+package object
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"math/big"
+)
+
+// Numbers that are equal are the same hash key whatever their type, so
+// {1: "a"}[1.0] and {1: "a"}[1.0d] both find "a". Integral values all hash
+// like the Integer (or BigInteger) of the same value, -0.0 included. The other
+// floats hash their bits, every NaN sharing one key, and the other decimals
+// hash their normalized digits. A float and a decimal with a fraction never
+// share a key since they cannot be compared either
+
+// canonicalNaN is the one bit pattern every NaN key hashes to
+const canonicalNaN = 0x7ff8000000000001
+
+// integerHashKey hashes any integer value, an int64 hashes the same as an Integer
+func integerHashKey(value *big.Int) HashKey {
+	if value.IsInt64() {
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(value.Int64())}
+	}
+	h := fnv.New64a()
+	if value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	h.Write(value.Bytes())
+	return HashKey{Type: BIG_INTEGER_OBJ, Value: h.Sum64()}
+}
+
+func floatHashKey(value float64) HashKey {
+	switch {
+	case math.IsNaN(value):
+		return HashKey{Type: FLOAT_OBJ, Value: canonicalNaN}
+	case math.IsInf(value, 0) || value != math.Trunc(value):
+		return HashKey{Type: FLOAT_OBJ, Value: math.Float64bits(value)}
+	case value >= math.MinInt64 && value < math.MaxInt64:
+		return HashKey{Type: INTEGER_OBJ, Value: uint64(int64(value))}
+	default:
+		i, _ := big.NewFloat(value).Int(nil)
+		return integerHashKey(i)
+	}
+}
+
+// AsHashable returns obj as a hash key when it can be one. Arrays implement
+// Hashable but only frozen ones holding hashable elements are usable as keys,
+// a key that could change would get lost in the hash. Tuples are usable when
+// their elements are
+func AsHashable(obj Object) (Hashable, bool) {
+	switch obj := obj.(type) {
+	case *Array:
+		if !obj.Frozen || !allHashable(obj.Elements) {
+			return nil, false
+		}
+		return obj, true
+	case *Tuple:
+		if !allHashable(obj.Elements) {
+			return nil, false
+		}
+		return obj, true
+	case Hashable:
+		return obj, true
+	default:
+		return nil, false
+	}
+}
+
+func allHashable(elements []Object) bool {
+	for _, element := range elements {
+		if _, ok := AsHashable(element); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compositeHashKey hashes the keys of elements in order, the elements must be hashable
+func compositeHashKey(t ObjectType, elements []Object) HashKey {
+	h := fnv.New64a()
+	buf := make([]byte, 8)
+	for _, element := range elements {
+		key := element.(Hashable).HashKey()
+		h.Write([]byte(key.Type))
+		binary.LittleEndian.PutUint64(buf, key.Value)
+		h.Write(buf)
+	}
+	return HashKey{Type: t, Value: h.Sum64()}
+}
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math/big"
+	"sort"
 	"strings"
 	"yap/ast"
+	"yap/token"
 )
 
 const (
@@ -21,6 +24,10 @@ const (
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
 	FOR_OBJ          = "FOR"
+	MODULE_OBJ       = "MODULE"
+	BIG_INTEGER_OBJ  = "BIG_INTEGER"
+	SET_OBJ          = "SET"
+	TUPLE_OBJ        = "TUPLE"
 )
 
 type ObjectType string
@@ -48,6 +55,30 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// BigInteger holds the integers that do not fit in an int64. Arithmetic turns
+// them back into an Integer as soon as the result fits again, so the same
+// number is never held by both types
+type BigInteger struct {
+	Value *big.Int
+}
+
+func (b *BigInteger) Inspect() string {
+	return b.Value.String()
+}
+
+func (b *BigInteger) Type() ObjectType {
+	return BIG_INTEGER_OBJ
+}
+
+func (b *BigInteger) HashKey() HashKey {
+	return integerHashKey(b.Value)
+}
+
+// IsInteger tells whether t is one of the two integer types
+func IsInteger(t ObjectType) bool {
+	return t == INTEGER_OBJ || t == BIG_INTEGER_OBJ
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -95,11 +126,23 @@ func (f *Float) Type() ObjectType {
 }
 
 func (f *Float) HashKey() HashKey {
-	return HashKey{Type: f.Type(), Value: uint64(f.Value)}
+	return floatHashKey(f.Value)
 }
 
 type ReturnValue struct {
 	Value Object
+	// Tail is set instead of Value by `sayless f(...)`, the call is made by
+	// whoever unwraps the return value so the stack does not grow
+	Tail *TailCall
+}
+
+// TailCall is a call in tail position that has not been made yet
+type TailCall struct {
+	Function *Function
+	Args     []Object
+	Named    map[string]Object
+	// Token is the call's position, for the errors of binding its arguments
+	Token token.Token
 }
 
 func (r *ReturnValue) Type() ObjectType {
@@ -107,11 +150,16 @@ func (r *ReturnValue) Type() ObjectType {
 }
 
 func (r *ReturnValue) Inspect() string {
+	if r.Tail != nil {
+		return "tail call"
+	}
 	return r.Value.Inspect()
 }
 
 type Error struct {
 	Message string
+	Line    int
+	Column  int
 }
 
 func (e *Error) Type() ObjectType {
@@ -125,6 +173,8 @@ func (e *Error) Inspect() string {
 type Function struct {
 	Name       *ast.Identifier
 	Parameters []*ast.Identifier
+	Defaults   []ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Enviroment
 }
@@ -133,17 +183,27 @@ func (f *Function) Type() ObjectType {
 	return FUNCTION_OBJ
 }
 
+// Signature is the head of the function like `func add(a, b = 1)`, used in errors
+func (f *Function) Signature() string {
+	return ast.Signature(f.Name, f.Parameters, f.Defaults, f.Rest)
+}
+
+// Required is how many arguments the function needs at least
+func (f *Function) Required() int {
+	required := 0
+	for i := range f.Parameters {
+		if i >= len(f.Defaults) || f.Defaults[i] == nil {
+			required = i + 1
+		}
+	}
+	return required
+}
+
 func (f *Function) Inspect() string {
 	var msg bytes.Buffer
 
-	params := []string{}
-	for _, p := range f.Parameters {
-		params = append(params, p.String())
-	}
-	msg.WriteString("func")
-	msg.WriteString("(")
-	msg.WriteString(strings.Join(params, ", "))
-	msg.WriteString(") {\n")
+	msg.WriteString(f.Signature())
+	msg.WriteString(" {\n")
 	msg.WriteString(f.Body.String())
 	msg.WriteString("\n}")
 
@@ -213,14 +273,51 @@ func (b *Builtin) Inspect() string {
 	return "builtin function"
 }
 
+// ElementTypes maps the names usable in a typed array literal like `[int: 1, 2]` to their type
+var ElementTypes = map[string]ObjectType{
+	"int":     INTEGER_OBJ,
+	"float":   FLOAT_OBJ,
+	"string":  STRING_OBJ,
+	"bool":    BOOLEAN_OBJ,
+	"array":   ARRAY_OBJ,
+	"hash":    HASH_OBJ,
+	"func":    FUNCTION_OBJ,
+	"decimal": DECIMAL_OBJ,
+	"set":     SET_OBJ,
+	"tuple":   TUPLE_OBJ,
+}
+
 type Array struct {
 	Elements []Object
+	// ElementType is only set for typed arrays, an empty one takes any element
+	ElementType ObjectType
+	// Frozen arrays cannot be changed, which lets them be hash keys
+	Frozen bool
+}
+
+// Accepts tells whether obj can be an element of the array
+func (a *Array) Accepts(obj Object) bool {
+	if a.ElementType == "" {
+		return true
+	}
+	if a.ElementType == FUNCTION_OBJ && obj.Type() == BUILTIN_OBJ {
+		return true
+	}
+	if a.ElementType == INTEGER_OBJ {
+		return IsInteger(obj.Type())
+	}
+	return obj.Type() == a.ElementType
 }
 
 func (a *Array) Type() ObjectType {
 	return ARRAY_OBJ
 }
 
+// HashKey is only meaningful for frozen arrays, see AsHashable
+func (a *Array) HashKey() HashKey {
+	return compositeHashKey(a.Type(), a.Elements)
+}
+
 func (a *Array) Inspect() string {
 	var msg bytes.Buffer
 
@@ -237,11 +334,51 @@ func (a *Array) Inspect() string {
 	return msg.String()
 }
 
+// Hash keeps its keys in Keys in the order they were first added,
+// everything that walks over a hash goes through Keys to stay deterministic
 type Hash struct {
 	Pairs map[HashKey]HashPair
 	Keys  []Object
 }
 
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair), Keys: []Object{}}
+}
+
+// Set adds the pair or replaces its value, a replaced key keeps its place
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, key.(Object))
+	}
+	h.Pairs[hashKey] = HashPair{Key: key.(Object), Value: value}
+}
+
+// Delete removes the pair for key and reports whether there was one
+func (h *Hash) Delete(key Hashable) bool {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		return false
+	}
+	delete(h.Pairs, hashKey)
+	for i, k := range h.Keys {
+		if k.(Hashable).HashKey() == hashKey {
+			h.Keys = append(h.Keys[:i:i], h.Keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// OrderedPairs returns the pairs in insertion order
+func (h *Hash) OrderedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Keys))
+	for _, key := range h.Keys {
+		pairs = append(pairs, h.Pairs[key.(Hashable).HashKey()])
+	}
+	return pairs
+}
+
 func (h *Hash) Type() ObjectType {
 	return HASH_OBJ
 }
@@ -251,7 +388,7 @@ func (h *Hash) Inspect() string {
 
 	pairs := []string{}
 
-	for _, pair := range h.Pairs {
+	for _, pair := range h.OrderedPairs() {
 		pairs = append(pairs, fmt.Sprintf("%s: %s",
 			pair.Key.Inspect(), pair.Value.Inspect()))
 	}
@@ -276,3 +413,44 @@ type HashPair struct {
 type Hashable interface {
 	HashKey() HashKey
 }
+
+// Module is the namespace created by importing a file, its members are the
+// top-level bindings of the file that do not start with an underscore
+type Module struct {
+	Name string
+	Path string
+	Env  *Enviroment
+}
+
+func (m *Module) Type() ObjectType {
+	return MODULE_OBJ
+}
+
+func (m *Module) Inspect() string {
+	var msg bytes.Buffer
+
+	msg.WriteString("module " + m.Name + " {")
+	msg.WriteString(strings.Join(m.Exports(), ", "))
+	msg.WriteString("}")
+
+	return msg.String()
+}
+
+func (m *Module) Member(name string) (Object, bool) {
+	if strings.HasPrefix(name, "_") {
+		return nil, false
+	}
+	obj, ok := m.Env.store[name]
+	return obj, ok
+}
+
+func (m *Module) Exports() []string {
+	names := []string{}
+	for name := range m.Env.store {
+		if !strings.HasPrefix(name, "_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,12 +1,16 @@
 package object
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+)
 
 func TestStringHashKey(t *testing.T) {
 	hello1 := &String{Value: "hello there"}
 	hello2 := &String{Value: "hello there"}
 	diff1 := &String{Value: "my name is jeff"}
-	deff2 := &String{Value: "my name is jeff"}
+	diff2 := &String{Value: "my name is jeff"}
 
 	if hello1.HashKey() != hello2.HashKey() {
 		t.Errorf("String with the same content have different hash key")
@@ -20,3 +24,112 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("String with different content have the same hash keys")
 	}
 }
+
+func TestHashOrder(t *testing.T) {
+	hash := NewHash()
+	hash.Set(&String{Value: "b"}, &Integer{Value: 1})
+	hash.Set(&String{Value: "a"}, &Integer{Value: 2})
+	hash.Set(&String{Value: "c"}, &Integer{Value: 3})
+	hash.Set(&String{Value: "b"}, &Integer{Value: 4})
+
+	if hash.Inspect() != "{b: 4, a: 2, c: 3}" {
+		t.Errorf("Inspect error: expect={b: 4, a: 2, c: 3}, got=%s", hash.Inspect())
+	}
+
+	if !hash.Delete(&String{Value: "a"}) {
+		t.Errorf("Delete error: existing key was not deleted")
+	}
+	if hash.Delete(&String{Value: "a"}) {
+		t.Errorf("Delete error: missing key was deleted")
+	}
+	if hash.Inspect() != "{b: 4, c: 3}" {
+		t.Errorf("Inspect error: expect={b: 4, c: 3}, got=%s", hash.Inspect())
+	}
+}
+
+func TestBigIntegerHashKey(t *testing.T) {
+	big1 := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	big2 := &BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}
+	negative := &BigInteger{Value: new(big.Int).Neg(big1.Value)}
+
+	if big1.HashKey() != big2.HashKey() {
+		t.Errorf("BigInteger with the same value have different hash key")
+	}
+	if big1.HashKey() == negative.HashKey() {
+		t.Errorf("BigInteger with opposite signs have the same hash key")
+	}
+}
+
+func TestDecimalHashKey(t *testing.T) {
+	short, _ := ParseDecimal("1.5")
+	long, _ := ParseDecimal("1.500")
+	other, _ := ParseDecimal("-1.5")
+
+	if short.HashKey() != long.HashKey() {
+		t.Errorf("Decimal with the same value have different hash key")
+	}
+	if short.HashKey() == other.HashKey() {
+		t.Errorf("Decimal with different values have the same hash key")
+	}
+	if long.Inspect() != "1.500" {
+		t.Errorf("Inspect error: expect=1.500, got=%s", long.Inspect())
+	}
+}
+
+func TestNumberHashKey(t *testing.T) {
+	half, _ := ParseDecimal("0.5")
+	one, _ := ParseDecimal("1.00")
+
+	same := [][]Hashable{
+		{&Integer{Value: 1}, &Float{Value: 1.0}, one},
+		{&Integer{Value: 0}, &Float{Value: 0}, &Float{Value: math.Copysign(0, -1)}},
+		{&Float{Value: math.NaN()}, &Float{Value: -math.NaN()}},
+		{&BigInteger{Value: new(big.Int).Lsh(big.NewInt(1), 70)}, &Float{Value: math.Ldexp(1, 70)}},
+	}
+	for _, keys := range same {
+		for _, key := range keys[1:] {
+			if keys[0].HashKey() != key.HashKey() {
+				t.Errorf("%s and %s have different hash keys",
+					keys[0].(Object).Inspect(), key.(Object).Inspect())
+			}
+		}
+	}
+
+	different := [][2]Hashable{
+		{&Float{Value: 1.2}, &Float{Value: 1.7}},
+		{&Float{Value: 0.5}, half},
+		{&Float{Value: 0.5}, &Integer{Value: 0}},
+		{&Float{Value: math.Inf(1)}, &Float{Value: math.Inf(-1)}},
+		{&Integer{Value: 1}, &Boolean{Value: true}},
+		{&Integer{Value: 1}, &String{Value: "1"}},
+	}
+	for _, keys := range different {
+		if keys[0].HashKey() == keys[1].HashKey() {
+			t.Errorf("%s and %s have the same hash key",
+				keys[0].(Object).Inspect(), keys[1].(Object).Inspect())
+		}
+	}
+}
+
+func TestArrayHashKey(t *testing.T) {
+	frozen := &Array{Elements: []Object{&Integer{Value: 1}, &String{Value: "a"}}, Frozen: true}
+	same := &Array{Elements: []Object{&Float{Value: 1}, &String{Value: "a"}}, Frozen: true}
+	swapped := &Array{Elements: []Object{&String{Value: "a"}, &Integer{Value: 1}}, Frozen: true}
+
+	if frozen.HashKey() != same.HashKey() {
+		t.Errorf("frozen arrays with equal elements have different hash keys")
+	}
+	if frozen.HashKey() == swapped.HashKey() {
+		t.Errorf("frozen arrays with elements in a different order have the same hash key")
+	}
+
+	if _, ok := AsHashable(frozen); !ok {
+		t.Errorf("frozen array is not hashable")
+	}
+	if _, ok := AsHashable(&Array{Elements: frozen.Elements}); ok {
+		t.Errorf("array that is not frozen is hashable")
+	}
+	if _, ok := AsHashable(&Array{Elements: []Object{NewHash()}, Frozen: true}); ok {
+		t.Errorf("frozen array holding a hash is hashable")
+	}
+}
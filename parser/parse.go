@@ -1,41 +1,81 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
+	"math/big"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"yap/ast"
 	"yap/lexer"
 	"yap/token"
 )
 
+// The precedence levels from the loosest to the tightest binding:
+//
+//	TERNARY      a ? b : c              right, a ? b : c ? d : e is a ? b : (c ? d : e)
+//	EQUALS       ==  !=                 left
+//	LESSGREATER  <  >                   left
+//	BITOR        |                      left
+//	BITXOR       ^                      left
+//	BITAND       &                      left
+//	SHIFT        <<  >>                 left
+//	SUM          +  -                   left
+//	PRODUCT      *  /  %                left
+//	PREFIX       -x  !x  ~x  ++x  --x
+//	POWER        **                     right, 2 ** 3 ** 2 is 2 ** 9 and -2 ** 2 is -(2 ** 2)
+//	CALL         f(x)
+//	INDEX        a[i]  a.b  x++  x--
+//
+// The assignments `=`, `+=` and the others are statements and not operators, they
+// take a whole expression on their right
 const (
 	_ int = iota
 	LOWEST
+	TERNARY
 	EQUALS
 	LESSGREATER
+	BITOR
+	BITXOR
+	BITAND
+	SHIFT
 	SUM
 	PRODUCT
 	PREFIX
+	POWER
 	CALL
 	INDEX
 )
 
 var precedence = map[token.TokenType]int{
+	token.TERNARY:   TERNARY,
 	token.EQ:        EQUALS,
 	token.NEQ:       EQUALS,
+	token.LT:        LESSGREATER,
+	token.GT:        LESSGREATER,
+	token.PIPE:      BITOR,
+	token.CARET:     BITXOR,
+	token.AMPERSAND: BITAND,
+	token.LSHIFT:    SHIFT,
+	token.RSHIFT:    SHIFT,
 	token.PLUS:      SUM,
 	token.MINUS:     SUM,
 	token.ASTERISK:  PRODUCT,
 	token.DASH:      PRODUCT,
-	token.LT:        LESSGREATER,
-	token.GT:        LESSGREATER,
-	token.TERNARY:   LESSGREATER,
-	token.LPAREN:    CALL,
-	token.POWER:     PRODUCT,
 	token.MOD:       PRODUCT,
-	token.DECREMENT: SUM,
-	token.INCREMENT: SUM,
+	token.POWER:     POWER,
+	token.LPAREN:    CALL,
 	token.LBRACKET:  INDEX,
+	token.DOT:       INDEX,
+	token.DECREMENT: INDEX,
+	token.INCREMENT: INDEX,
+}
+
+// rightAssociative are the operators that group from the right
+var rightAssociative = map[token.TokenType]bool{
+	token.POWER:   true,
+	token.TERNARY: true,
 }
 
 type Parser struct {
@@ -44,6 +84,11 @@ type Parser struct {
 	curToken  token.Token
 	peekToken token.Token
 	errors    []string
+	// panicking is set by an error and cleared by synchronize, the errors in between
+	// are left out since they only follow from the first one
+	panicking bool
+	// braces is how many `{` before the current token are not closed yet
+	braces int
 
 	prefixParseFns  map[token.TokenType]prefixParseFn
 	infixPerseFns   map[token.TokenType]infixParseFn
@@ -66,8 +111,10 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
 	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
+	p.registerPrefix(token.DECIMAL, p.parseDecimalLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBoolean)
 	p.registerPrefix(token.FALSE, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
@@ -76,6 +123,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(token.DECREMENT, p.parsePrefixExpression)
 	p.registerPrefix(token.INCREMENT, p.parsePrefixExpression)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.STRING_START, p.parseInterpolatedString)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 
@@ -90,10 +138,15 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.MOD, p.parseInfixExpression)
 	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.LSHIFT, p.parseInfixExpression)
+	p.registerInfix(token.RSHIFT, p.parseInfixExpression)
 	p.registerInfix(token.TERNARY, p.parseTernaryExpression)
-	p.registerInfix(token.COLON, p.parseTernaryExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	p.postfixParseFns = make(map[token.TokenType]postfixParseFn)
 	p.registerPostfix(token.INCREMENT, p.parsePostfixExpression)
@@ -109,18 +162,43 @@ func (p *Parser) parseIdentifier() ast.Expression {
 }
 
 func (p *Parser) nextToken() {
+	switch p.curToken.Type {
+	case token.LBRACE:
+		p.braces++
+	case token.RBRACE:
+		p.braces--
+	}
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
 }
 
+// Errors returns the errors of the lexer, then the ones of the parser
 func (p *Parser) Errors() []string {
-	return p.errors
+	return append(append([]string{}, p.l.Errors()...), p.errors...)
 }
 
-// This create the error of mismatch token and log it into p.errors
-func (p *Parser) peekError(token token.TokenType) {
-	msg := fmt.Sprintf("expected next token to be '%s', got '%s' instead", token, p.peekToken.Type)
+// This create the error of mismatch token and log it into p.errors, expected
+// is every token that could have been next, like ',' or ')' in a list
+func (p *Parser) peekError(expected ...token.TokenType) {
+	names := make([]string, len(expected))
+	for i, t := range expected {
+		names[i] = fmt.Sprintf("'%s'", t)
+	}
+	want := names[len(names)-1]
+	if len(names) > 1 {
+		want = strings.Join(names[:len(names)-1], ", ") + " or " + want
+	}
+	p.errorAt(p.peekToken, "expected next token to be %s, got '%s' instead", want, p.peekToken.Type)
+}
+
+// errorAt records an error at the position of tok, unless the statement already has one
+func (p *Parser) errorAt(tok token.Token, format string, args ...interface{}) {
+	if p.panicking {
+		return
+	}
+	msg := fmt.Sprintf("%d:%d: ", tok.Line, tok.Column) + fmt.Sprintf(format, args...)
 	p.errors = append(p.errors, msg)
+	p.panicking = true
 }
 
 func (p *Parser) ParserProgram() *ast.Program {
@@ -129,7 +207,12 @@ func (p *Parser) ParserProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for !p.curTokenIs(token.EOF) {
+		start, braces, panicking := p.curToken, p.braces, p.panicking
 		stmt := p.parseStatement()
+		if p.panicking && !panicking {
+			p.synchronize(start, braces)
+			continue
+		}
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
@@ -138,8 +221,45 @@ func (p *Parser) ParserProgram() *ast.Program {
 	return program
 }
 
+// statementStarts are the tokens that can only begin a statement
+var statementStarts = map[token.TokenType]bool{
+	token.LET:    true,
+	token.GLOBAL: true,
+	token.CONST:  true,
+	token.RETURN: true,
+	token.FOR:    true,
+	token.IMPORT: true,
+	token.IF:     true,
+}
+
+// synchronize skips the rest of a statement that had an error, so that one mistake
+// gives one error instead of one for every token after it. start is the first token
+// of the statement and braces how deep in `{` it is. It stops on the next statement:
+// after the `;` ending this one, on the `}` closing the block or on a token that can
+// only start a statement. Blocks inside the statement are skipped whole, and it
+// always moves past start
+func (p *Parser) synchronize(start token.Token, braces int) {
+	defer func() { p.panicking = false }()
+
+	for !p.curTokenIs(token.EOF) {
+		if p.braces <= braces {
+			if p.curTokenIs(token.SEMICOLON) {
+				p.nextToken()
+				return
+			}
+			if p.curToken != start && (p.curTokenIs(token.RBRACE) || statementStarts[p.curToken.Type]) {
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch {
+	case p.curToken.Type == token.SEMICOLON:
+		// an empty statement, like the `;` after a for loop
+		return nil
 	case p.curToken.Type == token.LET:
 		return p.parseLetStatement()
 	case p.curToken.Type == token.GLOBAL:
@@ -152,6 +272,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseIdentStatement()
 	case p.curToken.Type == token.FOR:
 		return p.parseForLiteral()
+	case p.curToken.Type == token.IMPORT:
+		return p.parseImportStatement()
 	default:
 		return p.parseExpressionstatement()
 	}
@@ -160,6 +282,9 @@ func (p *Parser) parseStatement() ast.Statement {
 func (p *Parser) parseLetStatement() *ast.SayStatement {
 	stmt := &ast.SayStatement{Token: p.curToken}
 
+	if _, ok := patternEnds[p.peekToken.Type]; ok {
+		return p.parseDestructuring(stmt)
+	}
 	if !p.expectPeek(token.IDENT) {
 		return nil
 	}
@@ -173,9 +298,11 @@ func (p *Parser) parseLetStatement() *ast.SayStatement {
 		return stmt
 	}
 
-	if !p.expectPeek(token.ASSIGN) {
+	if !p.peekTokenIs(token.ASSIGN) {
+		p.peekError(token.ASSIGN, token.SEMICOLON)
 		return nil
 	}
+	p.nextToken()
 
 	p.nextToken()
 
@@ -188,6 +315,109 @@ func (p *Parser) parseLetStatement() *ast.SayStatement {
 	return stmt
 }
 
+// parseDestructuring parses `propose [a, b] = pair;`, the current token is propose
+func (p *Parser) parseDestructuring(stmt *ast.SayStatement) *ast.SayStatement {
+	p.nextToken()
+	stmt.Pattern = p.parsePattern()
+	if stmt.Pattern == nil {
+		return nil
+	}
+
+	if !p.expectPeek(token.ASSIGN) {
+		return nil
+	}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+var patternEnds = map[token.TokenType]token.TokenType{
+	token.LBRACKET: token.RBRACKET,
+	token.LPAREN:   token.RPAREN,
+	token.LBRACE:   token.RBRACE,
+}
+
+// parsePattern parses [a, b], (a, b) or {a, b} with their nested patterns,
+// defaults like `b = 2` and a last `...rest`. The current token is the opening one
+func (p *Parser) parsePattern() *ast.Pattern {
+	pattern := &ast.Pattern{Token: p.curToken}
+	end := patternEnds[p.curToken.Type]
+	isHash := p.curTokenIs(token.LBRACE)
+
+	for !p.peekTokenIs(end) {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.peekTokenIs(end) {
+				p.errorAt(p.curToken, "...%s must be the last element of the pattern", pattern.Rest.Value)
+				return nil
+			}
+			break
+		}
+
+		element := &ast.PatternElement{}
+		if isHash {
+			if !p.curTokenIs(token.IDENT) {
+				p.errorAt(p.curToken, "cannot destructure into %s, expected a name", p.curToken.Literal)
+				return nil
+			}
+			element.Key = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			element.Target = element.Key
+			if p.peekTokenIs(token.COLON) {
+				p.nextToken()
+				p.nextToken()
+				element.Target = p.parsePatternTarget()
+			}
+		} else {
+			element.Target = p.parsePatternTarget()
+		}
+		if element.Target == nil {
+			return nil
+		}
+
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			element.Default = p.parseExpression(LOWEST)
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectListEnd(end) {
+		return nil
+	}
+	return pattern
+}
+
+// parsePatternTarget parses what a pattern element is bound to: a name or a nested pattern
+func (p *Parser) parsePatternTarget() ast.Node {
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET, token.LPAREN, token.LBRACE:
+		if pattern := p.parsePattern(); pattern != nil {
+			return pattern
+		}
+		return nil
+	default:
+		p.errorAt(p.curToken, "cannot destructure into %s, expected a name", p.curToken.Literal)
+		return nil
+	}
+}
+
 func (p *Parser) parseGlobalStatement() *ast.GlobalStatement {
 	stmt := &ast.GlobalStatement{Token: p.curToken}
 
@@ -204,7 +434,7 @@ func (p *Parser) parseGlobalStatement() *ast.GlobalStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
-	if !p.peekTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -228,7 +458,7 @@ func (p *Parser) parseConstStatement() *ast.ConstStaement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
-	if !p.peekTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 
@@ -238,11 +468,18 @@ func (p *Parser) parseConstStatement() *ast.ConstStaement {
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.curToken}
 
+	// a bare `sayless` returns null
+	if p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) || p.peekTokenIs(token.EOF) {
+		if p.peekTokenIs(token.SEMICOLON) {
+			p.nextToken()
+		}
+		return stmt
+	}
 	p.nextToken()
 
 	stmt.ReturnValue = p.parseExpression(LOWEST)
 
-	for !p.curTokenIs(token.SEMICOLON) {
+	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
 	return stmt
@@ -266,6 +503,17 @@ func (p *Parser) expectPeek(token token.TokenType) bool {
 	}
 }
 
+// expectListEnd is expectPeek for the end of a comma separated list, the error
+// also names the comma that could have come instead
+func (p *Parser) expectListEnd(end token.TokenType) bool {
+	if p.peekTokenIs(end) {
+		p.nextToken()
+		return true
+	}
+	p.peekError(token.COMMA, end)
+	return false
+}
+
 func (p *Parser) registerPrefix(tokenType token.TokenType, fn prefixParseFn) {
 	p.prefixParseFns[tokenType] = fn
 }
@@ -278,10 +526,24 @@ func (p *Parser) registerPostfix(tokenType token.TokenType, fn postfixParseFn) {
 	p.postfixParseFns[tokenType] = fn
 }
 
-func (p *Parser) parseExpressionstatement() *ast.ExpressionStatement {
+// assignOperators are the tokens that turn an expression statement into an AssignStatement
+var assignOperators = map[token.TokenType]bool{
+	token.ASSIGN:          true,
+	token.PLUS_ASSIGN:     true,
+	token.MINUS_ASSIGN:    true,
+	token.ASTERISK_ASSIGN: true,
+	token.DASH_ASSIGN:     true,
+	token.MOD_ASSIGN:      true,
+	token.POWER_ASSIGN:    true,
+}
+
+func (p *Parser) parseExpressionstatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 
 	stmt.Expression = p.parseExpression(LOWEST)
+	if stmt.Expression != nil && assignOperators[p.peekToken.Type] {
+		return p.parseAssignStatement(stmt.Expression)
+	}
 
 	//Skipping the semicolon token for each statement
 	if p.peekTokenIs(token.SEMICOLON) {
@@ -299,13 +561,12 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	}
 	leftExp := prefix()
 
-	if p.peekTokenIs(token.INCREMENT) || p.peekTokenIs(token.DECREMENT) {
-		p.nextToken()
-		postfix := p.postfixParseFns[p.curToken.Type]
-		leftExp = postfix(leftExp)
-	}
-
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
+		if postfix, ok := p.postfixParseFns[p.peekToken.Type]; ok {
+			p.nextToken()
+			leftExp = postfix(leftExp)
+			continue
+		}
 		infix := p.infixPerseFns[p.peekToken.Type]
 		if infix == nil {
 			return leftExp
@@ -319,23 +580,45 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 func (p *Parser) parseFloatLiteral() ast.Expression {
 	lit := &ast.FloatLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+	value, err := strconv.ParseFloat(strings.ReplaceAll(p.curToken.Literal, "_", ""), 64)
 	if err != nil {
-		msg := fmt.Sprintf("Could not parse %q as a float", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, "Could not parse %q as a float", p.curToken.Literal)
 		return nil
 	}
 	lit.Value = value
 	return lit
 }
 
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	value := strings.ReplaceAll(strings.TrimSuffix(p.curToken.Literal, "d"), "_", "")
+	return &ast.DecimalLiteral{Token: p.curToken, Value: value}
+}
+
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.curToken}
 
-	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
+	// the lexer made sure the literal is well formed, like 1_000 or 0xFF
+	digits, base := strings.ReplaceAll(p.curToken.Literal, "_", ""), 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			digits, base = digits[2:], 16
+		case 'b', 'B':
+			digits, base = digits[2:], 2
+		case 'o', 'O':
+			digits, base = digits[2:], 8
+		}
+	}
+
+	value, err := strconv.ParseInt(digits, base, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		if value, ok := new(big.Int).SetString(digits, base); ok {
+			lit.Big = value
+			return lit
+		}
+	}
 	if err != nil {
-		msg := fmt.Sprintf("Could not parse %q as an integer", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, "Could not parse %q as an integer", p.curToken.Literal)
 		return nil
 	}
 	lit.Value = value
@@ -343,8 +626,12 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	if t == token.ILLEGAL {
+		// the lexer already reported what is wrong with it
+		p.panicking = true
+		return
+	}
+	p.errorAt(p.curToken, "no prefix parse function for %s found", t)
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
@@ -382,6 +669,10 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	if rightAssociative[p.curToken.Type] {
+		// the right side takes in the operators of the same level too
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 	return expression
@@ -400,21 +691,43 @@ func (p *Parser) parseBoolean() ast.Expression {
 	val := &ast.Boolean{Token: p.curToken}
 	boolean, ok := strconv.ParseBool(string(p.curToken.Type))
 	if ok != nil {
-		msg := fmt.Sprintf("Could not parse %s as boolean value", p.curToken.Type)
-		p.errors = append(p.errors, msg)
+		p.errorAt(p.curToken, "Could not parse %s as boolean value", p.curToken.Type)
 	}
 	val.Value = boolean
 	return val
 }
 
+// parseGroupedExpression also parses tuples, a comma after the first
+// expression is what makes `(1, 2)` or `(1,)` a tuple and `()` is the empty one
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	tuple := &ast.TupleLiteral{Token: p.curToken, Elements: []ast.Expression{}}
+	if p.peekTokenIs(token.RPAREN) {
+		p.nextToken()
+		return tuple
+	}
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
-	if !p.expectPeek(token.RPAREN) {
+	if !p.peekTokenIs(token.COMMA) {
+		if !p.expectListEnd(token.RPAREN) {
+			return nil
+		}
+		return exp
+	}
+
+	tuple.Elements = append(tuple.Elements, exp)
+	for p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if p.peekTokenIs(token.RPAREN) {
+			break
+		}
+		p.nextToken()
+		tuple.Elements = append(tuple.Elements, p.parseExpression(LOWEST))
+	}
+	if !p.expectListEnd(token.RPAREN) {
 		return nil
 	}
-	return exp
+	return tuple
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
@@ -463,7 +776,12 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.curTokenIs(token.RBRACE) && !p.curTokenIs(token.EOF) {
+		start, braces, panicking := p.curToken, p.braces, p.panicking
 		stmt := p.parseStatement()
+		if p.panicking && !panicking {
+			p.synchronize(start, braces)
+			continue
+		}
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
@@ -497,38 +815,47 @@ func (p *Parser) parseElifExpression() []ast.ElifEpxression {
 	return expression
 }
 
+// parseTernaryExpression parses `condition ? a : b`, the current token is the `?`.
+// The middle goes up to its `:` like it was in brackets, the alternative is parsed
+// at the ternary level so `a ? b : c ? d : e` nests on the right
 func (p *Parser) parseTernaryExpression(condition ast.Expression) ast.Expression {
 	expression := &ast.TernaryExpression{
 		Token:     p.curToken,
 		Condition: condition,
 	}
-	p.parseTernaryBlockStatement(expression)
+
+	p.nextToken()
+	expression.Consequence = p.parseTernaryBranch(LOWEST)
+	if expression.Consequence == nil {
+		return nil
+	}
+	if p.peekTokenIs(token.FORMAT_SPEC) {
+		// the lexer read the `:` of the ternary as the start of the format
+		p.errorAt(p.peekToken, "wrap a ternary in parentheses inside an interpolation, its ':' starts the format")
+		return nil
+	}
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+	p.nextToken()
+	expression.Alternative = p.parseTernaryBranch(TERNARY - 1)
+	if expression.Alternative == nil {
+		return nil
+	}
 	return expression
 }
 
-func (p *Parser) parseTernaryBlockStatement(ternary *ast.TernaryExpression) {
-	consequence := &ast.BlockStatement{Token: p.curToken}
-	consequence.Statements = []ast.Statement{}
-	for !p.curTokenIs(token.COLON) {
-		p.nextToken()
-		stmt := p.parseStatement()
-		if stmt != nil {
-			consequence.Statements = append(consequence.Statements, stmt)
-		}
-		p.nextToken()
+// parseTernaryBranch wraps one side of a ternary into a block of one expression statement
+func (p *Parser) parseTernaryBranch(precedence int) *ast.BlockStatement {
+	tok := p.curToken
+	exp := p.parseExpression(precedence)
+	if exp == nil {
+		return nil
 	}
-	ternary.Consequence = consequence
-	alternative := &ast.BlockStatement{Token: p.curToken}
-	alternative.Statements = []ast.Statement{}
-	for !p.curTokenIs(token.EOF) {
-		p.nextToken()
-		stmt := p.parseStatement()
-		if stmt != nil {
-			alternative.Statements = append(alternative.Statements, stmt)
-		}
-		p.nextToken()
+	return &ast.BlockStatement{
+		Token:      tok,
+		Statements: []ast.Statement{&ast.ExpressionStatement{Token: tok, Expression: exp}},
 	}
-	ternary.Alternative = alternative
 }
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
@@ -543,7 +870,9 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 		return nil
 	}
 
-	literal.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(literal) {
+		return nil
+	}
 
 	if !p.expectPeek(token.LBRACE) {
 		return nil
@@ -552,28 +881,52 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	return literal
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifier := []*ast.Identifier{}
+// parseFunctionParameters parses `(a, b = 1, ...rest)` into literal. Parameters
+// with a default come after the ones without and the rest parameter comes last
+func (p *Parser) parseFunctionParameters(literal *ast.FunctionExpression) bool {
+	literal.Parameters = []*ast.Identifier{}
+	literal.Defaults = []ast.Expression{}
 
-	if p.peekTokenIs(token.RPAREN) {
+	for !p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return identifier
-	}
 
-	p.nextToken()
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
-	identifier = append(identifier, ident)
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			literal.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			if !p.peekTokenIs(token.RPAREN) {
+				p.errorAt(p.curToken, "...%s must be the last parameter", literal.Rest.Value)
+				return false
+			}
+			break
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+		if !p.curTokenIs(token.IDENT) {
+			p.errorAt(p.curToken, "expected a parameter name, got %s", p.curToken.Literal)
+			return false
+		}
+		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+		var value ast.Expression
+		if p.peekTokenIs(token.ASSIGN) {
+			p.nextToken()
+			p.nextToken()
+			value = p.parseExpression(LOWEST)
+		} else if len(literal.Defaults) > 0 && literal.Defaults[len(literal.Defaults)-1] != nil {
+			p.errorAt(p.curToken, "parameter %s without a default cannot come after one with a default", ident.Value)
+			return false
+		}
+		literal.Parameters = append(literal.Parameters, ident)
+		literal.Defaults = append(literal.Defaults, value)
+
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		identifier = append(identifier, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
-	return identifier
+	return p.expectListEnd(token.RPAREN)
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
@@ -581,33 +934,40 @@ func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 		Token:    p.curToken,
 		Function: function,
 	}
-	exp.Arguments = p.parseCallArgument()
+	if !p.parseCallArgument(exp) {
+		return nil
+	}
 
 	return exp
 }
 
-func (p *Parser) parseCallArgument() []ast.Expression {
-	args := []ast.Expression{}
+// parseCallArgument parses the arguments of exp, the `name = value` ones come last
+func (p *Parser) parseCallArgument(exp *ast.CallExpression) bool {
+	exp.Arguments = []ast.Expression{}
 
-	if p.peekTokenIs(token.RPAREN) {
+	for !p.peekTokenIs(token.RPAREN) {
 		p.nextToken()
-		return args
-	}
 
-	p.nextToken()
-	args = append(args, p.parseExpression(LOWEST))
+		if p.curTokenIs(token.IDENT) && p.peekTokenIs(token.ASSIGN) {
+			name := &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			p.nextToken()
+			p.nextToken()
+			exp.NamedArguments = append(exp.NamedArguments,
+				&ast.NamedArgument{Name: name, Value: p.parseExpression(LOWEST)})
+		} else if len(exp.NamedArguments) > 0 {
+			p.errorAt(p.curToken, "positional argument %s cannot come after a named one", p.curToken.Literal)
+			return false
+		} else {
+			exp.Arguments = append(exp.Arguments, p.parseExpression(LOWEST))
+		}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+		if !p.peekTokenIs(token.COMMA) {
+			break
+		}
 		p.nextToken()
-		args = append(args, p.parseExpression(LOWEST))
-	}
-
-	if !p.expectPeek(token.RPAREN) {
-		return nil
 	}
 
-	return args
+	return p.expectListEnd(token.RPAREN)
 }
 
 func (p *Parser) parseIdentStatement() *ast.PotentialStatement {
@@ -623,15 +983,99 @@ func (p *Parser) parseIdentStatement() *ast.PotentialStatement {
 	return stmt
 }
 
+// parseAssignStatement parses `target op value;`, the peek token is the assignment operator.
+// The target has to be a name or an index, a plain `a = x` never gets here
+func (p *Parser) parseAssignStatement(target ast.Expression) ast.Statement {
+	p.nextToken()
+	stmt := &ast.AssignStatement{Token: p.curToken, Target: target, Operator: p.curToken.Literal}
+	p.nextToken()
+	stmt.Value = p.parseExpression(LOWEST)
+	if stmt.Value == nil || p.panicking {
+		// the target may be missing parts, which String cannot print
+		return nil
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression:
+		return stmt
+	default:
+		p.errorAt(stmt.Token, "cannot assign to %s, expected a name or an index", target.String())
+		return nil
+	}
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.curToken, Literal: p.curToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.curToken, Texts: []string{p.curToken.Literal}}
+
+	for !p.curTokenIs(token.STRING_END) {
+		p.nextToken()
+		if p.curTokenIs(token.STRING_MIDDLE) || p.curTokenIs(token.STRING_END) {
+			p.errorAt(p.curToken, "empty interpolation {} in a string")
+			return nil
+		}
+		exp := p.parseExpression(LOWEST)
+		if exp == nil || p.panicking {
+			return nil
+		}
+		format := ""
+		if p.peekTokenIs(token.FORMAT_SPEC) {
+			p.nextToken()
+			format = p.curToken.Literal
+		}
+
+		if !p.peekTokenIs(token.STRING_MIDDLE) && !p.peekTokenIs(token.STRING_END) {
+			p.errorAt(p.peekToken, "expected } to close the interpolation of %s, got '%s' instead",
+				exp.String(), p.peekToken.Type)
+			return nil
+		}
+		p.nextToken()
+		str.Values = append(str.Values, exp)
+		str.Formats = append(str.Formats, format)
+		str.Texts = append(str.Texts, p.curToken.Literal)
+	}
+
+	return str
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.curToken}
 
-	array.Elements = p.parseExpressionList(token.RBRACKET)
+	if p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		array.Elements = []ast.Expression{}
+		return array
+	}
 
+	p.nextToken()
+	// `[int: 1, 2]` declares the type of every element, `func` is a keyword
+	// so it is picked up before it can start a function literal
+	var first ast.Expression
+	if p.curTokenIs(token.FUNCTION) && p.peekTokenIs(token.COLON) {
+		first = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	} else {
+		first = p.parseExpression(LOWEST)
+	}
+
+	if ident, ok := first.(*ast.Identifier); ok && p.peekTokenIs(token.COLON) {
+		array.ElementType = ident
+		p.nextToken()
+		if p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			array.Elements = []ast.Expression{}
+			return array
+		}
+		p.nextToken()
+		first = p.parseExpression(LOWEST)
+	}
+
+	array.Elements = p.parseExpressionListFrom([]ast.Expression{first}, token.RBRACKET)
 	if array.Elements == nil {
 		return nil
 	}
@@ -649,6 +1093,11 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	p.nextToken()
 	list = append(list, p.parseExpression(LOWEST))
 
+	return p.parseExpressionListFrom(list, end)
+}
+
+// parseExpressionListFrom parses the rest of a comma separated list after its first elements
+func (p *Parser) parseExpressionListFrom(list []ast.Expression, end token.TokenType) []ast.Expression {
 	for p.peekTokenIs(token.COMMA) {
 		p.nextToken()
 		p.nextToken()
@@ -657,7 +1106,7 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 		list = append(list, curExp)
 	}
 
-	if !p.expectPeek(end) {
+	if !p.expectListEnd(end) {
 		return nil
 	}
 
@@ -685,16 +1134,37 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 		p.nextToken()
 		key := p.parseExpression(LOWEST)
 
-		if !p.expectPeek(token.COLON) {
+		// {1, 2} is a set, only the first element tells which one it is
+		if len(hash.Keys) == 0 && (p.peekTokenIs(token.COMMA) || p.peekTokenIs(token.RBRACE)) {
+			set := &ast.SetLiteral{Token: hash.Token}
+			set.Elements = p.parseExpressionListFrom([]ast.Expression{key}, token.RBRACE)
+			if set.Elements == nil {
+				return nil
+			}
+			return set
+		}
+
+		if !p.peekTokenIs(token.COLON) {
+			if len(hash.Keys) == 0 {
+				p.peekError(token.COLON, token.COMMA, token.RBRACE)
+			} else {
+				p.peekError(token.COLON)
+			}
 			return nil
 		}
+		p.nextToken()
 
 		p.nextToken()
 		value := p.parseExpression(LOWEST)
 		hash.Pairs[key] = value
+		hash.Keys = append(hash.Keys, key)
 
-		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
-			return nil
+		if !p.peekTokenIs(token.RBRACE) {
+			if !p.peekTokenIs(token.COMMA) {
+				p.peekError(token.COMMA, token.RBRACE)
+				return nil
+			}
+			p.nextToken()
 		}
 	}
 
@@ -705,14 +1175,18 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	return hash
 }
 
-func (p *Parser) parseForLiteral() *ast.ForExpression {
+func (p *Parser) parseForLiteral() ast.Statement {
 	forStat := &ast.ForExpression{Token: p.curToken}
 
 	if !p.expectPeek(token.LPAREN) {
-		msg := fmt.Sprintf("Expecting \"(\", got %s", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
 		return nil
 	}
+	if p.peekTokenIs(token.IDENT) {
+		p.nextToken()
+		if p.peekTokenIs(token.IN) {
+			return p.parseForInLiteral(forStat.Token)
+		}
+	}
 
 	for !p.curTokenIs(token.RPAREN) {
 		if p.peekTokenIs(token.LET) {
@@ -728,15 +1202,12 @@ func (p *Parser) parseForLiteral() *ast.ForExpression {
 		if p.curTokenIs(token.SEMICOLON) {
 			p.nextToken()
 		} else if !p.curTokenIs(token.RPAREN) {
-			msg := fmt.Sprintf("Expected \")\" got %s", p.curToken.Literal)
-			p.errors = append(p.errors, msg)
+			p.errorAt(p.curToken, "expected next token to be ';' or ')', got '%s' instead", p.curToken.Type)
+			return nil
 		}
 	}
-	p.nextToken()
 
-	if !p.curTokenIs(token.LBRACE) {
-		msg := fmt.Sprintf("Expected \"{\", got %s", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
 
@@ -744,3 +1215,65 @@ func (p *Parser) parseForLiteral() *ast.ForExpression {
 
 	return forStat
 }
+
+// for (x in xs) { ... }, the current token is x
+func (p *Parser) parseForInLiteral(tok token.Token) ast.Statement {
+	forIn := &ast.ForInExpression{Token: tok}
+	forIn.Variable = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	p.nextToken()
+	p.nextToken()
+
+	forIn.Iterable = p.parseExpression(LOWEST)
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+	forIn.Statements = p.parseBlockStatement()
+
+	return forIn
+}
+
+// yoink "path/to/file.yap" as name;
+// Without `as`, the module is bound to the file name without its extension
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectPeek(token.STRING) {
+		return nil
+	}
+	stmt.Path = p.curToken.Literal
+
+	if p.peekTokenIs(token.IDENT) && p.peekToken.Literal == "as" {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	} else {
+		base := filepath.Base(stmt.Path)
+		name := strings.TrimSuffix(base, filepath.Ext(base))
+		if token.LookupIdent(name) != token.IDENT || !lexer.IsIdentifier(name) {
+			p.errorAt(p.curToken, "cannot use %q as a module name, use `as` to name it", name)
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.curToken, Value: name}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parseMemberExpression(left ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Left: left}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Member = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 	"testing"
+	"time"
 	"yap/ast"
 	"yap/lexer"
 )
@@ -238,6 +239,30 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"a + b * c + d / e - f",
 			"(((a + (b * c)) + (d / e)) - f)",
 		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b << 2 + c",
+			"(a & (b << (2 + c)))",
+		},
+		{
+			"a << b >> c",
+			"((a << b) >> c)",
+		},
+		{
+			"a & b == c | d",
+			"((a & b) == (c | d))",
+		},
+		{
+			"~a & ~b",
+			"((~a) & (~b))",
+		},
+		{
+			"a < b | c",
+			"(a < (b | c))",
+		},
 		{
 			"3 + 4; -5 * 5",
 			"(3 + 4)((-5) * 5)",
@@ -310,6 +335,94 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a++ + b",
+			"((a++) + b)",
+		},
+		{
+			"-a++",
+			"(-(a++))",
+		},
+		{
+			"++a * 2",
+			"((++a) * 2)",
+		},
+		{
+			"arr[0]++",
+			"((arr[0])++)",
+		},
+		{
+			"add(a++, b--)",
+			"add((a++), (b--))",
+		},
+		{
+			"a---b",
+			"((a--) - b)",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"2 ** -1",
+			"(2 ** (-1))",
+		},
+		{
+			"a * b ** c",
+			"(a * (b ** c))",
+		},
+		{
+			"a ** b * c",
+			"((a ** b) * c)",
+		},
+		{
+			"!a ** 2",
+			"(!(a ** 2))",
+		},
+		{
+			"a[0] ** f(x) ** 2",
+			"((a[0]) ** (f(x) ** 2))",
+		},
+		{
+			"a % b * c / d",
+			"(((a % b) * c) / d)",
+		},
+		{
+			"a == b ? c : d",
+			"((a == b) ? c : d)",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)",
+		},
+		{
+			"a < b ? x + 1 : y * 2",
+			"((a < b) ? (x + 1) : (y * 2))",
+		},
+		{
+			"f(a ? b : c, d)",
+			"f((a ? b : c), d)",
+		},
+		{
+			"[a ? 1 : 2][0]",
+			"([(a ? 1 : 2)][0])",
+		},
+		{
+			"a == b < c",
+			"(a == (b < c))",
+		},
+		{
+			"a != b == c",
+			"((a != b) == c)",
+		},
 	}
 
 	for _, test := range tests {
@@ -325,6 +438,93 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 	}
 }
 
+func TestBigIntegerLiteral(t *testing.T) {
+	input := "123456789012345678901234567890;"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParserProgram()
+	checkParserError(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	lit, ok := stmt.Expression.(*ast.IntegerLiteral)
+	if !ok {
+		t.Fatalf("Expression error: expect=ast.IntegerLiteral, got=%T", stmt.Expression)
+	}
+	if lit.Big == nil || lit.Big.String() != "123456789012345678901234567890" {
+		t.Errorf("Big value error: expect=123456789012345678901234567890, got=%v", lit.Big)
+	}
+	if lit.String() != "123456789012345678901234567890" {
+		t.Errorf("String error: got=%s", lit.String())
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0xFF", 255},
+		{"0Xff", 255},
+		{"0b1010", 10},
+		{"0o17", 15},
+		{"1_000_000", 1000000},
+		{"0x7FFF_FFFF", 2147483647},
+		{"0", 0},
+		{"1e3", 1000.0},
+		{"2.5e-3", 0.0025},
+		{"1E+2", 100.0},
+		{".5", 0.5},
+		{"1_0.2_5", 10.25},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		switch expected := tt.expected.(type) {
+		case int:
+			lit, ok := stmt.Expression.(*ast.IntegerLiteral)
+			if !ok || lit.Value != int64(expected) {
+				t.Errorf("%s: expect=IntegerLiteral %d, got=%T (%+v)", tt.input, expected, stmt.Expression, stmt.Expression)
+			}
+		case float64:
+			lit, ok := stmt.Expression.(*ast.FloatLiteral)
+			if !ok || lit.Value != expected {
+				t.Errorf("%s: expect=FloatLiteral %g, got=%T (%+v)", tt.input, expected, stmt.Expression, stmt.Expression)
+			}
+		}
+	}
+
+	l := lexer.New("0xFFFF_FFFF_FFFF_FFFF;")
+	p := New(l)
+	program := p.ParserProgram()
+	checkParserError(t, p)
+	lit := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IntegerLiteral)
+	if lit.Big == nil || lit.Big.String() != "18446744073709551615" {
+		t.Errorf("Big value error: expect=18446744073709551615, got=%v", lit.Big)
+	}
+
+	l = lexer.New("1_000.50d;")
+	p = New(l)
+	program = p.ParserProgram()
+	checkParserError(t, p)
+	dec := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.DecimalLiteral)
+	if dec.Value != "1000.50" {
+		t.Errorf("Decimal value error: expect=1000.50, got=%s", dec.Value)
+	}
+
+	l = lexer.New("propose a = 0b12;")
+	p = New(l)
+	p.ParserProgram()
+	if len(p.Errors()) != 1 || p.Errors()[0] != "1:13: malformed number 0b12: unexpected '2'" {
+		t.Errorf("expected only the lexer error, got=%v", p.Errors())
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input         string
@@ -586,6 +786,26 @@ func TestTernaryStatement(t *testing.T) {
 	}
 }
 
+func TestTernaryErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ? b", "1:6: expected next token to be ':', got 'EOF' instead"},
+		{"a ? b c", "1:7: expected next token to be ':', got 'IDENT' instead"},
+		{"a ? b : ;", "1:9: no prefix parse function for ; found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParserProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `
 	func (a , b){
@@ -802,6 +1022,44 @@ func TestArrayLiteral(t *testing.T) {
 	}
 }
 
+func TestTypedArrayLiteral(t *testing.T) {
+	tests := []struct {
+		input       string
+		elementType string
+		expected    string
+	}{
+		{"[int: 1, 2 + 3]", "int", "[int: 1, (2 + 3)]"},
+		{"[string:]", "string", "[string:]"},
+		{"[func: len, func(x) { x }]", "func", "[func: len, func(x) x]"},
+		{"[1, \"a\", 2.5]", "", "[1, a, 2.5]"},
+		{"[]", "", "[]"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		array, ok := stmt.Expression.(*ast.ArrayLiteral)
+		if !ok {
+			t.Fatalf("Expression error: expect=ast.ArrayLiteral, got=%T", stmt.Expression)
+		}
+
+		elementType := ""
+		if array.ElementType != nil {
+			elementType = array.ElementType.Value
+		}
+		if elementType != tt.elementType {
+			t.Errorf("ElementType error: expect=%q, got=%q", tt.elementType, elementType)
+		}
+		if array.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, array.String())
+		}
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "arr[1+2];"
 
@@ -932,6 +1190,84 @@ func TestParsingHashLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input        string
+		expectedPath string
+		expectedName string
+	}{
+		{`yoink "lib/math.yap";`, "lib/math.yap", "math"},
+		{`yoink "../shared/strings.yap" as str;`, "../shared/strings.yap", "str"},
+		{`yoink "utils"`, "utils", "utils"},
+		{`yoink "lib/café.yap"`, "lib/café.yap", "café"},
+	}
+
+	for _, test := range tests {
+		l := lexer.New(test.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("Statement length error: expect=1, got=%d", len(program.Statements))
+		}
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("Statement error: expect= *ast.ImportStatement, got=%T", program.Statements[0])
+		}
+		if stmt.Path != test.expectedPath {
+			t.Errorf("Path error: expect=%s, got=%s", test.expectedPath, stmt.Path)
+		}
+		if stmt.Name.Value != test.expectedName {
+			t.Errorf("Name error: expect=%s, got=%s", test.expectedName, stmt.Name.Value)
+		}
+	}
+}
+
+func TestImportStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`yoink math;`, "1:7: expected next token to be 'STRING', got 'IDENT' instead"},
+		{`yoink "my-lib.yap";`, "1:7: cannot use \"my-lib\" as a module name, use `as` to name it"},
+		{`yoink "lib2.yap";`, "1:7: cannot use \"lib2\" as a module name, use `as` to name it"},
+		{`yoink "lib.yap" as 5;`, "1:20: expected next token to be 'IDENT', got 'INT' instead"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		p.ParserProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != test.expected {
+			t.Errorf("%s: error mismatch: expect=%q, got=%q", test.input, test.expected, p.Errors())
+		}
+	}
+}
+
+func TestMemberExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"math.pi", "(math.pi)"},
+		{"math.square(2) + 1", "((math.square)(2) + 1)"},
+		{"a.b.c[1]", "(((a.b).c)[1])"},
+		{"-lib.value * 2", "((-(lib.value)) * 2)"},
+	}
+
+	for _, test := range tests {
+		p := New(lexer.New(test.input))
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != test.expected {
+			t.Errorf("Parsing error: expect=%s, got=%s", test.expected, program.String())
+		}
+	}
+}
+
 func checkParserError(t *testing.T, p *Parser) {
 	errors := p.Errors()
 	if len(errors) == 0 {
@@ -1082,3 +1418,375 @@ func testFloatLiteral(t *testing.T, exp ast.Expression, val float64) bool {
 
 	return true
 }
+
+func TestSetAndTupleLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1, 2 + 3}", "{1, (2 + 3)}"},
+		{"{\"a\"}", "{a}"},
+		{"{1: 2}", "{1: 2}"},
+		{"{}", "{}"},
+		{"(1, 2)", "(1, 2)"},
+		{"(1,)", "(1,)"},
+		{"(1, 2,)", "(1, 2)"},
+		{"()", "()"},
+		{"(1)", "1"},
+		{"((1, 2), (3,))", "((1, 2), (3,))"},
+		{"f((1, 2))", "f((1, 2))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestForInAndTuplePattern(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for (x in xs) { x; }", "for(x in xs){x}"},
+		{"for (i < 3; ++i) { i; }", "for((i < 3), (++i)){i}"},
+		{"propose (a, b) = pair;", "propose (a, b) = pair;"},
+		{"propose (a,) = (1,);", "propose (a,) = (1,);"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	l := lexer.New("propose (a, 1) = pair;")
+	p := New(l)
+	p.ParserProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "1:13: cannot destructure into 1, expected a name" {
+		t.Errorf("expected a pattern error, got=%v", p.Errors())
+	}
+}
+
+func TestDestructuringPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"propose [a, b] = pair;", "propose [a, b] = pair;"},
+		{"propose [a, b = 1 + 1, ...rest] = xs;", "propose [a, b = (1 + 1), ...rest] = xs;"},
+		{"propose {name, age: years = 0} = person;", "propose {name, age: years = 0} = person;"},
+		{"propose {name, ...others} = person;", "propose {name, ...others} = person;"},
+		{"propose [[a, b], {pos: (x, y)}] = v;", "propose [[a, b], {pos: (x, y)}] = v;"},
+		{"propose [] = v;", "propose [] = v;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"propose [...rest, a] = v;", "1:13: ...rest must be the last element of the pattern"},
+		{"propose {\"name\"} = v;", "1:10: cannot destructure into name, expected a name"},
+		{"propose [a + 1] = v;", "1:12: expected next token to be ',' or ']', got '+' instead"},
+		{"propose [a, b];", "1:15: expected next token to be '=', got ';' instead"},
+	}
+
+	for _, tt := range errors {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParserProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestAssignStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a += 1;", "a += 1;"},
+		{"a -= b * 2;", "a -= (b * 2);"},
+		{"a **= 2", "a **= 2;"},
+		{"arr[0] = 5;", "(arr[0]) = 5;"},
+		{"arr[i + 1] %= 2;", "(arr[(i + 1)]) %= 2;"},
+		{`counts["x"] /= 2;`, "(counts[x]) /= 2;"},
+		{"grid[1][2] *= -1;", "((grid[1])[2]) *= (-1);"},
+		{"a = 1;", "propose a = 1;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement, got=%d", tt.input, len(program.Statements))
+		}
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"(a + 1) += 1;", "1:9: cannot assign to (a + 1), expected a name or an index"},
+		{"f() = 2;", "1:5: cannot assign to f(), expected a name or an index"},
+		{"5 *= 2;", "1:3: cannot assign to 5, expected a name or an index"},
+	}
+
+	for _, tt := range errors {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParserProgram()
+		if len(p.Errors()) != 1 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestFunctionSignatures(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func(a, b = 1 + 1) { a }", "func(a, b = (1 + 1)) a"},
+		{"func(...rest) { rest }", "func(...rest) rest"},
+		{"func add(a, b = 1, ...rest) { a }", "func add(a, b = 1, ...rest) a"},
+		{"f(1, sep = \", \")", "f(1, sep = , )"},
+		{"f(a = 1, b = 2)", "f(a = 1, b = 2)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"func(a = 1, b) { a }", "1:13: parameter b without a default cannot come after one with a default"},
+		{"func(...rest, a) { a }", "1:9: ...rest must be the last parameter"},
+		{"func(1) { 1 }", "1:6: expected a parameter name, got 1"},
+		{"f(a = 1, 2)", "1:10: positional argument 2 cannot come after a named one"},
+	}
+
+	for _, tt := range errors {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParserProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"score: {total}/{num}"`, "score: {total}/{num}"},
+		{`"{a + b * 2:>8.2} left"`, "{(a + (b * 2)):>8.2} left"},
+		{`"{ {"k": 1}["k"] }"`, "{({k: 1}[k])}"},
+		{`"{[int: 1, 2]}"`, "{[int: 1, 2]}"},
+		{`"{f("x{y}")}"`, "{f(x{y})}"},
+		{`"{(a ? b : c):>4}"`, "{(a ? b : c):>4}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+		checkParserError(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("String error: expect=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`"a{}b"`, "1:4: empty interpolation {} in a string"},
+		{`"{a b}"`, "1:5: expected } to close the interpolation of a, got 'IDENT' instead"},
+		{`"{a ? b : c}"`, "1:9: wrap a ternary in parentheses inside an interpolation, its ':' starts the format"},
+		{`"{a}\q"`, `1:5: invalid escape sequence \q`},
+	}
+
+	for _, tt := range errors {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParserProgram()
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("%s: expected error %q, got=%v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input      string
+		errors     []string
+		statements int
+	}{
+		{"sayless 5", nil, 1},
+		{"sayless", nil, 1},
+		{"sayless +", []string{"1:9: no prefix parse function for + found"}, 0},
+		{"propose a = ;\npropose b = 2;\nb", []string{"1:13: no prefix parse function for ; found"}, 2},
+		{"propose 5 = 1; propose c = 3; c", []string{"1:9: expected next token to be 'IDENT', got 'INT' instead"}, 2},
+		{"propose a 5; a", []string{"1:11: expected next token to be '=' or ';', got 'INT' instead"}, 1},
+		{"propose x = 1 +\npropose y = 2;", []string{"2:1: no prefix parse function for LET found"}, 1},
+		{"func f(1) { yap(1); yap(2); }; f()", []string{"1:8: expected a parameter name, got 1"}, 1},
+		{"perhaps (a) { propose = 1; b } c", []string{"1:23: expected next token to be 'IDENT', got '=' instead"}, 2},
+		{"propose f = func() { propose a = ; a + 1 }; f()", []string{"1:34: no prefix parse function for ; found"}, 2},
+		{"} propose a = 1;", []string{"1:1: no prefix parse function for } found"}, 1},
+		{"propose [a, b = ] = x; propose c = 1", []string{"1:17: no prefix parse function for ] found"}, 1},
+		{"propose a = ; propose b = ;", []string{
+			"1:13: no prefix parse function for ; found",
+			"1:27: no prefix parse function for ; found",
+		}, 0},
+		{"f(a b); g(1)", []string{"1:5: expected next token to be ',' or ')', got 'IDENT' instead"}, 1},
+		{"[1, 2 3]", []string{"1:7: expected next token to be ',' or ']', got 'INT' instead"}, 0},
+		{"{1: 2 3}", []string{"1:7: expected next token to be ',' or '}', got 'INT' instead"}, 0},
+		{"{1 2}", []string{"1:4: expected next token to be ':', ',' or '}', got 'INT' instead"}, 0},
+		{"(1, 2", []string{"1:6: expected next token to be ',' or ')', got 'EOF' instead"}, 0},
+		{"func(a, b { a }", []string{"1:11: expected next token to be ',' or ')', got '{' instead"}, 0},
+		{"for (a < 3", []string{"1:11: expected next token to be ';' or ')', got 'EOF' instead"}, 0},
+		{"for (propose i = 0; i < 3", []string{"1:26: expected next token to be ';' or ')', got 'EOF' instead"}, 0},
+		{"for (a < 3) a", []string{"1:13: expected next token to be '{', got 'IDENT' instead"}, 0},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParserProgram()
+
+		if fmt.Sprint(p.Errors()) != fmt.Sprint(tt.errors) {
+			t.Errorf("%q: expected errors %q, got=%q", tt.input, tt.errors, p.Errors())
+		}
+		if len(program.Statements) != tt.statements {
+			t.Errorf("%q: expected %d statements, got=%d", tt.input, tt.statements, len(program.Statements))
+		}
+	}
+}
+
+func TestOptionalSemicolons(t *testing.T) {
+	tests := []struct {
+		input      string
+		semicolons string
+	}{
+		{"propose a = 1\npropose b = a + 2\nb", "propose a = 1; propose b = a + 2; b"},
+		{"worldwide a = 1\nackchyually b = 2\nyap(a, b)", "worldwide a = 1; ackchyually b = 2; yap(a, b);"},
+		{"a = 1\na += 2\narr[0]++\n--b", "a = 1; a += 2; arr[0]++; --b;"},
+		{"propose a = 1;\n\n\npropose b = 2;\n", "propose a = 1; propose b = 2;"},
+		{"propose a = 1 +\n    2 *\n    3\na", "propose a = 1 + 2 * 3; a"},
+		{"propose s = add(\n    1,\n    2\n)\ns", "propose s = add(1, 2); s"},
+		{"propose arr = [\n    1,\n    2\n]\narr", "propose arr = [1, 2]; arr"},
+		{"propose h = {\n    \"a\": 1,\n    \"b\": [1,\n        2]\n}\nh", `propose h = {"a": 1, "b": [1, 2]}; h`},
+		{"propose t = a > 1 ?\n    \"big\" :\n    \"small\"\nt", `propose t = a > 1 ? "big" : "small"; t`},
+		{"propose f = func(a,\n    b) {\n    propose c = a\n    c + b\n}\nf(1, 2)", "propose f = func(a, b) { propose c = a; c + b; }; f(1, 2);"},
+		{"func f() {\n    sayless 1\n}\nf()", "func f() { sayless 1; }; f();"},
+		{"func f(a) {\n    sayless\n    a\n}", "func f(a) { sayless; a; }"},
+		{"propose s = a\n{1, 2}", "propose s = a; {1, 2};"},
+		{"func f()\n{\n    1\n}", "func f() { 1 }"},
+		{"perhaps (a) {\n    b\n}\nperchance (c) {\n    d\n}\notherwise {\n    e\n}\nf", "perhaps (a) { b } perchance (c) { d } otherwise { e }; f"},
+		{"perhaps (a)\n{\n    b\n}\notherwise\n{\n    c\n}", "perhaps (a) { b } otherwise { c }"},
+		{"for (propose i = 0;\n    i < 3;\n    i++) {\n    yap(i)\n}\ni", "for (propose i = 0; i < 3; i++) { yap(i); }; i"},
+		{"for (x in xs) {\n    yap(x)\n};\nx", "for (x in xs) { yap(x); }; x"},
+		{"yoink \"lib.yap\"\nlib.f(1)", `yoink "lib.yap"; lib.f(1)`},
+	}
+
+	for _, tt := range tests {
+		lines := New(lexer.New(tt.input))
+		program := lines.ParserProgram()
+		checkParserError(t, lines)
+
+		semicolons := New(lexer.New(tt.semicolons))
+		expected := semicolons.ParserProgram()
+		checkParserError(t, semicolons)
+
+		if program.String() != expected.String() {
+			t.Errorf("%q: expected=%q, got=%q", tt.input, expected.String(), program.String())
+		}
+		if len(program.Statements) != len(expected.Statements) {
+			t.Errorf("%q: expected %d statements, got=%d", tt.input, len(expected.Statements), len(program.Statements))
+		}
+	}
+}
+
+func FuzzParser(f *testing.F) {
+	seeds := []string{
+		"propose a = 1 + 2 * 3;",
+		"func add(a, b = 1, ...rest) { sayless a + b; }; add(1, b = 2);",
+		"perhaps (a > 1) { yap(a); } elif (a < 0) { a++; } otherwise { --a; }",
+		"for (propose i = 0; i < 3; ++i) { arr[i] += i ** 2; }",
+		"for (x in [1, 2]) { counts[x] = x ? 1 : 2; }",
+		"propose [a, b = 2, ...rest] = xs; propose {name, age: years} = person;",
+		`yap("a{b:>8}c {d}", {1: 2}, {1, 2}, (1,), [int: 1, 2]);`,
+		"yoink \"lib.yap\" as lib; lib.f(1)",
+		"sayless",
+		"for (",
+		"propose a = func(",
+		"{[(",
+		"a ? b ? : c",
+		"} ) ] ; ;",
+		"0*00a=S*3;",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			p := New(lexer.New(input))
+			program := p.ParserProgram()
+			if len(p.Errors()) == 0 {
+				// a program without errors has every node filled in
+				_ = program.String()
+			}
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("the parser did not finish on %q", input)
+		}
+	})
+}
@@ -0,0 +1,184 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"yap/ast"
+	"yap/evaluator"
+	"yap/lexer"
+	"yap/object"
+	"yap/parser"
+)
+
+const (
+	FILE_SUFFIX = "_test.yap"
+	TEST_PREFIX = "test_"
+)
+
+type Result struct {
+	File    string
+	Name    string
+	Passed  bool
+	Message string
+	Line    int
+	Column  int
+}
+
+func (r Result) Position() string {
+	return fmt.Sprintf("%s:%d:%d", r.File, r.Line, r.Column)
+}
+
+// FindFiles expands every directory in paths into the test files below it.
+// Files given directly are kept even without the test suffix
+func FindFiles(paths []string) ([]string, error) {
+	files := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), FILE_SUFFIX) {
+				files = append(files, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// RunFile runs every `func test_*()` in the file whose name matches filter (nil runs all of them)
+func RunFile(path string, filter *regexp.Regexp) ([]Result, error) {
+	input, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.New(lexer.New(string(input)))
+	program := p.ParserProgram()
+	if len(p.Errors()) != 0 {
+		return nil, fmt.Errorf("%s: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	results := []Result{}
+	for _, test := range findTests(program) {
+		if filter != nil && !filter.MatchString(test.Name.Value) {
+			continue
+		}
+		results = append(results, runTest(path, program, test))
+	}
+	return results, nil
+}
+
+func findTests(program *ast.Program) []*ast.FunctionExpression {
+	tests := []*ast.FunctionExpression{}
+
+	for _, stmt := range program.Statements {
+		exp, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			continue
+		}
+		fn, ok := exp.Expression.(*ast.FunctionExpression)
+		if !ok || fn.Name == nil || len(fn.Parameters) != 0 {
+			continue
+		}
+		if strings.HasPrefix(fn.Name.Value, TEST_PREFIX) {
+			tests = append(tests, fn)
+		}
+	}
+	return tests
+}
+
+// runTest evaluates the whole file again in a fresh enviroment, so no test can see
+// what an earlier one left behind, then calls the test function
+func runTest(path string, program *ast.Program, test *ast.FunctionExpression) Result {
+	result := Result{
+		File:   path,
+		Name:   test.Name.Value,
+		Line:   test.Token.Line,
+		Column: test.Token.Column,
+	}
+
+	evaluator.Reset()
+	env := object.NewEnviroment()
+	env.SetFile(path)
+	outcome := evaluator.Eval(program, env)
+	if !failed(&result, outcome) {
+		fn, _ := env.Get(test.Name.Value)
+		outcome = evaluator.Call(fn)
+		failed(&result, outcome)
+	}
+	result.Passed = result.Message == ""
+	return result
+}
+
+func failed(result *Result, outcome object.Object) bool {
+	errObj, ok := outcome.(*object.Error)
+	if !ok {
+		return false
+	}
+
+	result.Message = errObj.Message
+	if errObj.Line != 0 {
+		result.Line = errObj.Line
+		result.Column = errObj.Column
+	}
+	return true
+}
+
+// Run runs the tests found in paths, reports each of them to out and returns whether all passed
+func Run(out io.Writer, paths []string, filter string) (bool, error) {
+	var pattern *regexp.Regexp
+	if filter != "" {
+		compiled, err := regexp.Compile(filter)
+		if err != nil {
+			return false, fmt.Errorf("invalid -run pattern: %s", err)
+		}
+		pattern = compiled
+	}
+
+	files, err := FindFiles(paths)
+	if err != nil {
+		return false, err
+	}
+
+	passed, failedCount := 0, 0
+	for _, file := range files {
+		results, err := RunFile(file, pattern)
+		if err != nil {
+			fmt.Fprintf(out, "FAIL %s\n\t%s\n", file, err)
+			failedCount++
+			continue
+		}
+
+		for _, result := range results {
+			if result.Passed {
+				fmt.Fprintf(out, "PASS %s %s\n", result.File, result.Name)
+				passed++
+			} else {
+				fmt.Fprintf(out, "FAIL %s %s\n\t%s\n", result.Position(), result.Name, result.Message)
+				failedCount++
+			}
+		}
+	}
+
+	fmt.Fprintf(out, "\n%d passed, %d failed\n", passed, failedCount)
+	return failedCount == 0, nil
+}
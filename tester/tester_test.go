@@ -0,0 +1,176 @@
+package tester
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Could not write %s: %s", path, err)
+	}
+	return path
+}
+
+func TestRunFile(t *testing.T) {
+	input := `propose counter = 0;
+func helper(x) { x * 2 }
+func test_pass() {
+    ++counter;
+    assertEqual(helper(2), 4);
+    assertEqual(counter, 1);
+}
+func test_isolated() {
+    ++counter;
+    assertEqual(counter, 1);
+}
+func test_fail() {
+    assertEqual(helper(2), 5);
+}
+func notATest() {
+    assert(false);
+}
+`
+	path := writeTestFile(t, t.TempDir(), "math_test.yap", input)
+
+	results, err := RunFile(path, nil)
+	if err != nil {
+		t.Fatalf("RunFile error: %s", err)
+	}
+
+	expected := []struct {
+		name    string
+		passed  bool
+		line    int
+		message string
+	}{
+		{"test_pass", true, 3, ""},
+		{"test_isolated", true, 8, ""},
+		{"test_fail", false, 13, "assertEqual failed: expect=5, got=4"},
+	}
+
+	if len(results) != len(expected) {
+		t.Fatalf("Result length error: expect=%d, got=%d", len(expected), len(results))
+	}
+
+	for i, test := range expected {
+		result := results[i]
+		if result.Name != test.name {
+			t.Errorf("Name error: expect=%s, got=%s", test.name, result.Name)
+		}
+		if result.Passed != test.passed {
+			t.Errorf("%s: Passed error: expect=%t, got=%t (%s)", test.name, test.passed, result.Passed, result.Message)
+		}
+		if result.Line != test.line {
+			t.Errorf("%s: Line error: expect=%d, got=%d", test.name, test.line, result.Line)
+		}
+		if result.Message != test.message {
+			t.Errorf("%s: Message error: expect=%q, got=%q", test.name, test.message, result.Message)
+		}
+	}
+}
+
+func TestRunFileFilter(t *testing.T) {
+	input := `
+func test_one() { assert(true) }
+func test_two() { assert(true) }
+func test_three() { assert(true) }
+`
+	path := writeTestFile(t, t.TempDir(), "filter_test.yap", input)
+
+	results, err := RunFile(path, regexp.MustCompile("t[wh]"))
+	if err != nil {
+		t.Fatalf("RunFile error: %s", err)
+	}
+
+	if len(results) != 2 || results[0].Name != "test_two" || results[1].Name != "test_three" {
+		t.Fatalf("Filter error: expect test_two and test_three, got=%+v", results)
+	}
+}
+
+func TestRunFileResetsSettings(t *testing.T) {
+	input := `
+func test_change() {
+    decimalPrecision(2);
+    decimalRounding("down");
+    maxCallDepth(5);
+}
+func deep(n) { perhaps (n == 0) { sayless 0; }; sayless 1 + deep(n - 1); }
+func test_defaults() {
+    assertEqual(1d / 3d, 0.3333333333333333d);
+    assertEqual(round(2.5d, 0), 2d);
+    assertEqual(deep(10), 10);
+}
+`
+	path := writeTestFile(t, t.TempDir(), "settings_test.yap", input)
+
+	results, err := RunFile(path, nil)
+	if err != nil {
+		t.Fatalf("RunFile error: %s", err)
+	}
+	for _, result := range results {
+		if !result.Passed {
+			t.Errorf("%s failed: %s", result.Name, result.Message)
+		}
+	}
+}
+
+func TestRunFileReimportsModules(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "counter.yap", `
+propose count = 0;
+func bump() { count = count + 1; count }
+`)
+	input := `
+yoink "counter.yap";
+func test_first() { assertEqual(counter.bump(), 1); }
+func test_second() { assertEqual(counter.bump(), 1); }
+`
+	path := writeTestFile(t, dir, "counter_test.yap", input)
+
+	results, err := RunFile(path, nil)
+	if err != nil {
+		t.Fatalf("RunFile error: %s", err)
+	}
+	for _, result := range results {
+		if !result.Passed {
+			t.Errorf("%s failed: %s", result.Name, result.Message)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "a_test.yap", "func test_a() { assert(1 < 2) }")
+	writeTestFile(t, dir, "b_test.yap", "func test_b() { assert(1 > 2, \"b is broken\") }")
+	writeTestFile(t, dir, "helper.yap", "func test_ignored() { assert(false) }")
+
+	var out bytes.Buffer
+	ok, err := Run(&out, []string{dir}, "")
+	if err != nil {
+		t.Fatalf("Run error: %s", err)
+	}
+	if ok {
+		t.Fatalf("Run should fail, output:\n%s", out.String())
+	}
+
+	output := out.String()
+	for _, expected := range []string{
+		"PASS " + filepath.Join(dir, "a_test.yap") + " test_a",
+		"FAIL " + filepath.Join(dir, "b_test.yap") + ":1:17 test_b",
+		"assert failed: b is broken (condition is not true)",
+		"1 passed, 1 failed",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Output error: expect to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "test_ignored") {
+		t.Errorf("Output error: helper.yap is not a test file, got:\n%s", output)
+	}
+}
@@ -5,15 +5,25 @@ type TokenType string
 type Token struct {
 	Type    TokenType
 	Literal string
+	Line    int
+	Column  int
 }
 
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
 
-	IDENT  = "IDENT"
-	INT    = "INT"
-	STRING = "STRING"
+	IDENT   = "IDENT"
+	INT     = "INT"
+	STRING  = "STRING"
+	DECIMAL = "DECIMAL"
+
+	// an interpolated string like "a{x}b{y:>4}c" is lexed as STRING_START "a", the
+	// tokens of x, STRING_MIDDLE "b", the tokens of y, FORMAT_SPEC ">4", STRING_END "c"
+	STRING_START  = "STRING_START"
+	STRING_MIDDLE = "STRING_MIDDLE"
+	STRING_END    = "STRING_END"
+	FORMAT_SPEC   = "FORMAT_SPEC"
 
 	ASSIGN    = "="
 	PLUS      = "+"
@@ -26,9 +36,26 @@ const (
 	DECREMENT = "--"
 	INCREMENT = "++"
 
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	LSHIFT    = "<<"
+	RSHIFT    = ">>"
+
+	// the compound assignments like `a += 1`
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	DASH_ASSIGN     = "/="
+	MOD_ASSIGN      = "%="
+	POWER_ASSIGN    = "**="
+
 	COMMA     = ","
 	SEMICOLON = ";"
 	COLON     = ":"
+	DOT       = "."
+	ELLIPSIS  = "..."
 	FLOAT     = "FLOAT"
 
 	LT      = "<"
@@ -55,6 +82,8 @@ const (
 	RETURN   = "RETURN"
 	GLOBAL   = "GLOBAL"
 	FOR      = "FOR"
+	IMPORT   = "IMPORT"
+	IN       = "IN"
 )
 
 var keywords = map[string]TokenType{
@@ -70,8 +99,9 @@ var keywords = map[string]TokenType{
 	"cap":         FALSE,
 	"ackchyually": CONST,
 	"worldwide":   GLOBAL,
-	".":           FLOAT,
 	"for":         FOR,
+	"yoink":       IMPORT,
+	"in":          IN,
 }
 
 func LookupIdent(indent string) TokenType {